@@ -0,0 +1,309 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// Config is the effective application configuration. Values are resolved in
+// the following order, later sources overriding earlier ones: `default` tags,
+// the YAML file referenced by CONFIG_FILE, the .env file and finally the
+// process environment.
+type Config struct {
+	App         AppConfig         `yaml:"app"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Logger      LoggerConfig      `yaml:"logger"`
+	Health      HealthConfig      `yaml:"health"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	RequestID   RequestIDConfig   `yaml:"requestId"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	OpenAPI     OpenAPIConfig     `yaml:"openapi"`
+	GRPC        GRPCConfig        `yaml:"grpc"`
+	GraphQL     GraphQLConfig     `yaml:"graphql"`
+	Events      EventsConfig      `yaml:"events"`
+	WebSocket   WebSocketConfig   `yaml:"websocket"`
+	Recovery    RecoveryConfig    `yaml:"recovery"`
+	Redaction   RedactionConfig   `yaml:"redaction"`
+	Admin       AdminConfig       `yaml:"admin"`
+	CORS        CORSConfig        `yaml:"cors"`
+	Security    SecurityConfig    `yaml:"security"`
+	TLS         TLSConfig         `yaml:"tls"`
+	Versioning  VersioningConfig  `yaml:"versioning"`
+	Compression CompressionConfig `yaml:"compression"`
+}
+
+type AppConfig struct {
+	Port            int           `yaml:"port" env:"APP_PORT" default:"7690" validate:"min=1,max=65535"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"APP_SHUTDOWN_TIMEOUT" default:"15s" validate:"gt=0"`
+
+	// The timeouts of the HTTP server, 0 disables one. Event streams and
+	// WebSocket connections are exempt from ReadTimeout and WriteTimeout.
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" env:"APP_READ_HEADER_TIMEOUT" default:"10s" validate:"min=0"`
+	ReadTimeout       time.Duration `yaml:"readTimeout" env:"APP_READ_TIMEOUT" default:"30s" validate:"min=0"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout" env:"APP_WRITE_TIMEOUT" default:"30s" validate:"min=0"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout" env:"APP_IDLE_TIMEOUT" default:"2m" validate:"min=0"`
+}
+
+type DatabaseConfig struct {
+	Username  string `yaml:"username" env:"DB_USERNAME" validate:"required"`
+	Password  string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Host      string `yaml:"host" env:"DB_HOST" validate:"required"`
+	Port      int    `yaml:"port" env:"DB_PORT" default:"3306" validate:"min=1,max=65535"`
+	Name      string `yaml:"name" env:"DB_NAME" validate:"required"`
+	Debug     bool   `yaml:"debug" env:"DB_DEBUG"`
+	Migration bool   `yaml:"migration" env:"DB_MIGRATION"`
+
+	// ReplicaHosts are host:port pairs serving reads, sharing the primary credentials.
+	ReplicaHosts []string `yaml:"replicaHosts" env:"DB_REPLICA_HOSTS"`
+
+	MaxOpenConns    int           `yaml:"maxOpenConns" env:"DB_MAX_OPEN_CONNS" default:"25" validate:"min=0"`
+	MaxIdleConns    int           `yaml:"maxIdleConns" env:"DB_MAX_IDLE_CONNS" default:"10" validate:"min=0"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime" env:"DB_CONN_MAX_LIFETIME" default:"30m" validate:"min=0"`
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime" env:"DB_CONN_MAX_IDLE_TIME" default:"5m" validate:"min=0"`
+
+	ConnectRetries    int           `yaml:"connectRetries" env:"DB_CONNECT_RETRIES" default:"5" validate:"min=0"`
+	ConnectBackoff    time.Duration `yaml:"connectBackoff" env:"DB_CONNECT_BACKOFF" default:"1s" validate:"gt=0"`
+	ConnectMaxBackoff time.Duration `yaml:"connectMaxBackoff" env:"DB_CONNECT_MAX_BACKOFF" default:"30s" validate:"gtefield=ConnectBackoff"`
+
+	TxIsolation string `yaml:"txIsolation" env:"DB_TX_ISOLATION" default:"repeatable-read" validate:"oneof=default read-uncommitted read-committed repeatable-read serializable"`
+
+	// ReadYourWritesHeader lets a caller force reads of a request to the primary.
+	ReadYourWritesHeader string `yaml:"readYourWritesHeader" env:"DB_READ_YOUR_WRITES_HEADER" default:"X-Read-Your-Writes"`
+}
+
+type LoggerConfig struct {
+	LogsWrite  bool   `yaml:"logsWrite" env:"LOGGER_LOGS_WRITE"`
+	FolderPath string `yaml:"folderPath" env:"LOGGER_FOLDER_PATH" default:"./logs"`
+	Backend    string `yaml:"backend" env:"LOGGER_BACKEND" default:"logres" validate:"oneof=logres slog"`
+	Level      string `yaml:"level" env:"LOGGER_LEVEL" default:"info" validate:"oneof=debug info warn error"`
+	// Levels override Level per logger name, such as usecase=debug. They can
+	// be changed at runtime through the admin endpoint.
+	Levels   []string             `yaml:"levels" env:"LOGGER_LEVELS" validate:"dive,loglevel"`
+	Sampling LoggerSamplingConfig `yaml:"sampling"`
+}
+
+// LoggerSamplingConfig applies to hot paths such as the incoming request log.
+// Within every Tick the first entries with the same level and title are
+// written, then every Thereafter-th one. Warnings and errors are never
+// sampled, First of 0 disables sampling.
+type LoggerSamplingConfig struct {
+	First      int           `yaml:"first" env:"LOGGER_SAMPLING_FIRST" default:"100" validate:"min=0"`
+	Thereafter int           `yaml:"thereafter" env:"LOGGER_SAMPLING_THEREAFTER" default:"100" validate:"min=0"`
+	Tick       time.Duration `yaml:"tick" env:"LOGGER_SAMPLING_TICK" default:"1s" validate:"gt=0"`
+}
+
+type AdminConfig struct {
+	// Tokens are the bearer tokens accepted on the /admin endpoints, which are
+	// refused entirely while none is set.
+	Tokens []string `yaml:"tokens" env:"ADMIN_TOKENS" secret:"true"`
+}
+
+type RedactionConfig struct {
+	// Headers are logged with their values masked.
+	Headers []string `yaml:"headers" env:"LOG_REDACT_HEADERS" default:"Authorization,Proxy-Authorization,Cookie,Set-Cookie,X-Api-Key"`
+	// Fields are masked in JSON and form bodies and in query strings. A field
+	// is a dotted path such as card.number, a single name matches at any depth
+	// and * matches any key or index.
+	Fields []string `yaml:"fields" env:"LOG_REDACT_FIELDS" default:"password,token,accessToken,access_token,refreshToken,secret,apiKey"`
+	// Patterns are the built-in value patterns masked anywhere.
+	Patterns []string `yaml:"patterns" env:"LOG_REDACT_PATTERNS" default:"email,card,bearer,jwt" validate:"dive,oneof=email card bearer jwt"`
+	// CustomPatterns are regular expressions masked anywhere. They are read from
+	// the YAML file only since they may contain commas.
+	CustomPatterns []string `yaml:"customPatterns" validate:"dive,regexp"`
+	// MaxBodySize truncates logged request and response bodies.
+	MaxBodySize int `yaml:"maxBodySize" env:"LOG_MAX_BODY_SIZE" default:"4096" validate:"min=1"`
+	// SkipBodyRoutes are routes such as "GET /metrics" logged without bodies.
+	SkipBodyRoutes []string `yaml:"skipBodyRoutes" env:"LOG_SKIP_BODY_ROUTES" default:"GET /metrics,GET /openapi.json,GET /docs,GET /graphiql"`
+}
+
+type HealthConfig struct {
+	CheckTimeout  time.Duration `yaml:"checkTimeout" env:"HEALTH_CHECK_TIMEOUT" default:"2s" validate:"gt=0"`
+	ShutdownDelay time.Duration `yaml:"shutdownDelay" env:"HEALTH_SHUTDOWN_DELAY" default:"5s" validate:"min=0"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" default:"none" validate:"oneof=none stdout file otlp"`
+	FilePath    string  `yaml:"filePath" env:"TRACING_FILE_PATH" default:"./logs/trace.log"`
+	SampleRatio float64 `yaml:"sampleRatio" env:"TRACING_SAMPLE_RATIO" default:"1" validate:"min=0,max=1"`
+}
+
+type RequestIDConfig struct {
+	// InboundHeaders are checked in order for a caller supplied request ID.
+	InboundHeaders []string `yaml:"inboundHeaders" env:"REQUEST_ID_HEADERS" default:"X-Request-ID,X-Correlation-ID"`
+	// ResponseHeader echoes the request ID back to the caller.
+	ResponseHeader string `yaml:"responseHeader" env:"REQUEST_ID_RESPONSE_HEADER" default:"X-Request-ID"`
+	// TrustInbound disables reading InboundHeaders when false.
+	TrustInbound bool `yaml:"trustInbound" env:"REQUEST_ID_TRUST_INBOUND" default:"true"`
+}
+
+type IdempotencyConfig struct {
+	Enabled bool          `yaml:"enabled" env:"IDEMPOTENCY_ENABLED" default:"true"`
+	Header  string        `yaml:"header" env:"IDEMPOTENCY_HEADER" default:"Idempotency-Key" validate:"required"`
+	TTL     time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" default:"24h" validate:"gt=0"`
+	// Store keeps the keys in this process with memory, which only holds for
+	// a single instance, or shares them between instances with database.
+	Store string `yaml:"store" env:"IDEMPOTENCY_STORE" default:"memory" validate:"oneof=memory database"`
+}
+
+type OpenAPIConfig struct {
+	// ValidateRequests rejects requests that do not match the OpenAPI document.
+	ValidateRequests bool `yaml:"validateRequests" env:"OPENAPI_VALIDATE_REQUESTS"`
+	// ValidateResponses logs responses that do not match the OpenAPI document.
+	// It buffers every response and is meant for debugging.
+	ValidateResponses bool `yaml:"validateResponses" env:"OPENAPI_VALIDATE_RESPONSES"`
+}
+
+type GRPCConfig struct {
+	Enabled bool `yaml:"enabled" env:"GRPC_ENABLED" default:"true"`
+	Port    int  `yaml:"port" env:"GRPC_PORT" default:"7691" validate:"min=1,max=65535"`
+	// Reflection lets tools such as grpcurl discover the services.
+	Reflection bool `yaml:"reflection" env:"GRPC_REFLECTION" default:"true"`
+}
+
+type GraphQLConfig struct {
+	Enabled bool `yaml:"enabled" env:"GRAPHQL_ENABLED" default:"true"`
+	// GraphiQL serves the in-browser IDE on /graphiql, meant for development.
+	GraphiQL      bool `yaml:"graphiql" env:"GRAPHQL_GRAPHIQL"`
+	MaxDepth      int  `yaml:"maxDepth" env:"GRAPHQL_MAX_DEPTH" default:"8" validate:"min=1"`
+	MaxComplexity int  `yaml:"maxComplexity" env:"GRAPHQL_MAX_COMPLEXITY" default:"300" validate:"min=1"`
+}
+
+type EventsConfig struct {
+	// ReplayBuffer is the number of recent events kept for Last-Event-ID resume.
+	ReplayBuffer int `yaml:"replayBuffer" env:"EVENTS_REPLAY_BUFFER" default:"1000" validate:"min=0"`
+	// ClientBuffer is the number of events queued per client before it is
+	// disconnected as a slow consumer.
+	ClientBuffer int           `yaml:"clientBuffer" env:"EVENTS_CLIENT_BUFFER" default:"64" validate:"min=1"`
+	Heartbeat    time.Duration `yaml:"heartbeat" env:"EVENTS_HEARTBEAT" default:"15s" validate:"gt=0"`
+}
+
+type WebSocketConfig struct {
+	Enabled bool `yaml:"enabled" env:"WEBSOCKET_ENABLED" default:"true"`
+	// Tokens are the bearer tokens accepted on connect, every connection is
+	// refused while it is empty.
+	Tokens []string `yaml:"tokens" env:"WEBSOCKET_TOKENS" secret:"true"`
+	// AllowedOrigins of browser clients besides the same origin, * allows any.
+	AllowedOrigins   []string      `yaml:"allowedOrigins" env:"WEBSOCKET_ALLOWED_ORIGINS"`
+	MaxConnections   int           `yaml:"maxConnections" env:"WEBSOCKET_MAX_CONNECTIONS" default:"1000" validate:"min=1"`
+	MaxSubscriptions int           `yaml:"maxSubscriptions" env:"WEBSOCKET_MAX_SUBSCRIPTIONS" default:"50" validate:"min=1"`
+	PingInterval     time.Duration `yaml:"pingInterval" env:"WEBSOCKET_PING_INTERVAL" default:"30s" validate:"gt=0"`
+	// PongTimeout is how long a ping may go unanswered before the connection is dropped.
+	PongTimeout time.Duration `yaml:"pongTimeout" env:"WEBSOCKET_PONG_TIMEOUT" default:"10s" validate:"gt=0"`
+	// IdleTimeout closes connections without any subscription.
+	IdleTimeout  time.Duration `yaml:"idleTimeout" env:"WEBSOCKET_IDLE_TIMEOUT" default:"1m" validate:"gt=0"`
+	WriteTimeout time.Duration `yaml:"writeTimeout" env:"WEBSOCKET_WRITE_TIMEOUT" default:"10s" validate:"gt=0"`
+}
+
+type RecoveryConfig struct {
+	// DumpFolder receives a JSON crash report per recovered panic, empty disables it.
+	DumpFolder string `yaml:"dumpFolder" env:"RECOVERY_DUMP_FOLDER"`
+	// MaxDumps is the number of most recent crash reports kept in DumpFolder.
+	MaxDumps int `yaml:"maxDumps" env:"RECOVERY_MAX_DUMPS" default:"100" validate:"min=1"`
+}
+
+type CORSConfig struct {
+	// AllowOrigins may call the API from a browser, * allows any origin and
+	// https://*.example.com any subdomain. CORS is disabled while it is empty.
+	AllowOrigins []string `yaml:"allowOrigins" env:"CORS_ALLOW_ORIGINS"`
+	// CredentialOrigins may also send cookies and the Authorization header.
+	// They are allowed origins as well and must be listed exactly.
+	CredentialOrigins []string      `yaml:"credentialOrigins" env:"CORS_CREDENTIAL_ORIGINS" validate:"dive,url,excludesall=*"`
+	AllowMethods      []string      `yaml:"allowMethods" env:"CORS_ALLOW_METHODS" default:"GET,HEAD,POST,PUT,PATCH,DELETE"`
+	AllowHeaders      []string      `yaml:"allowHeaders" env:"CORS_ALLOW_HEADERS" default:"Authorization,Content-Type,Idempotency-Key,Last-Event-ID,X-Request-ID,X-Read-Your-Writes"`
+	ExposeHeaders     []string      `yaml:"exposeHeaders" env:"CORS_EXPOSE_HEADERS" default:"X-Request-ID,Deprecation,Sunset,Link"`
+	MaxAge            time.Duration `yaml:"maxAge" env:"CORS_MAX_AGE" default:"10m" validate:"min=0"`
+}
+
+type SecurityConfig struct {
+	// HSTSMaxAge is sent on HTTPS requests, including those behind a proxy
+	// setting X-Forwarded-Proto, 0 disables it.
+	HSTSMaxAge            time.Duration `yaml:"hstsMaxAge" env:"SECURITY_HSTS_MAX_AGE" default:"8760h" validate:"min=0"`
+	HSTSIncludeSubdomains bool          `yaml:"hstsIncludeSubdomains" env:"SECURITY_HSTS_INCLUDE_SUBDOMAINS" default:"true"`
+	HSTSPreload           bool          `yaml:"hstsPreload" env:"SECURITY_HSTS_PRELOAD"`
+	ContentSecurityPolicy string        `yaml:"contentSecurityPolicy" env:"SECURITY_CSP" default:"default-src 'none'; frame-ancestors 'none'"`
+	// DocsContentSecurityPolicy applies to the HTML pages, which load their
+	// scripts from unpkg.com.
+	DocsContentSecurityPolicy string `yaml:"docsContentSecurityPolicy" env:"SECURITY_DOCS_CSP" default:"default-src 'self'; script-src 'self' 'unsafe-inline' https://unpkg.com; style-src 'self' 'unsafe-inline' https://unpkg.com; img-src 'self' data: https:; connect-src 'self'; frame-ancestors 'none'"`
+	ReferrerPolicy            string `yaml:"referrerPolicy" env:"SECURITY_REFERRER_POLICY" default:"no-referrer"`
+	FrameOptions              string `yaml:"frameOptions" env:"SECURITY_FRAME_OPTIONS" default:"DENY"`
+
+	// MaxBodySize is the largest request body in bytes, UploadRoutes such as
+	// "POST /v1/products/import" accept up to UploadMaxBodySize instead.
+	MaxBodySize       int      `yaml:"maxBodySize" env:"SECURITY_MAX_BODY_SIZE" default:"1048576" validate:"min=1"`
+	UploadMaxBodySize int      `yaml:"uploadMaxBodySize" env:"SECURITY_UPLOAD_MAX_BODY_SIZE" default:"33554432" validate:"min=1"`
+	UploadRoutes      []string `yaml:"uploadRoutes" env:"SECURITY_UPLOAD_ROUTES"`
+}
+
+type TLSConfig struct {
+	// CertFile and KeyFile serve HTTPS instead of HTTP. Both are reloaded
+	// when they change, as is ClientCAFile.
+	CertFile   string `yaml:"certFile" env:"TLS_CERT_FILE" validate:"required_with=KeyFile"`
+	KeyFile    string `yaml:"keyFile" env:"TLS_KEY_FILE" validate:"required_with=CertFile"`
+	MinVersion string `yaml:"minVersion" env:"TLS_MIN_VERSION" default:"1.2" validate:"oneof=1.2 1.3"`
+	HTTP2      bool   `yaml:"http2" env:"TLS_HTTP2" default:"true"`
+	// H2C serves HTTP/2 without TLS, for callers behind a proxy that
+	// terminates TLS. It only applies while CertFile is empty.
+	H2C bool `yaml:"h2c" env:"HTTP_H2C"`
+
+	// ClientAuth asks callers for a certificate signed by ClientCAFile: none,
+	// optional or require.
+	ClientAuth   string `yaml:"clientAuth" env:"TLS_CLIENT_AUTH" default:"none" validate:"oneof=none optional require"`
+	ClientCAFile string `yaml:"clientCAFile" env:"TLS_CLIENT_CA_FILE"`
+	// ClientIdentities map the common name of a client certificate to an
+	// identity, such as billing-service=billing. When set, certificates with
+	// another common name are refused.
+	ClientIdentities []string `yaml:"clientIdentities" env:"TLS_CLIENT_IDENTITIES" validate:"dive,contains=="`
+
+	// ReloadInterval is how often the files are checked for changes.
+	ReloadInterval time.Duration `yaml:"reloadInterval" env:"TLS_RELOAD_INTERVAL" default:"30s" validate:"gt=0"`
+}
+
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+type VersioningConfig struct {
+	// Default is the version of unversioned paths such as /products whose
+	// Accept header does not ask for one.
+	Default string `yaml:"default" env:"API_DEFAULT_VERSION" default:"v1" validate:"oneof=v1 v2"`
+	// Deprecated and Sunset map a version to the date it was deprecated and
+	// the date it stops being served, such as v1=2027-06-30. Responses of
+	// those versions carry the Deprecation and Sunset headers.
+	Deprecated []string `yaml:"deprecated" env:"API_DEPRECATED_VERSIONS" validate:"dive,versiondate"`
+	Sunset     []string `yaml:"sunset" env:"API_SUNSET_VERSIONS" validate:"dive,versiondate"`
+	// DeprecationLink documents the migration off deprecated versions.
+	DeprecationLink string `yaml:"deprecationLink" env:"API_DEPRECATION_LINK" validate:"omitempty,url"`
+}
+
+type CompressionConfig struct {
+	Enabled bool `yaml:"enabled" env:"COMPRESSION_ENABLED" default:"true"`
+	// Encodings in order of preference, for clients accepting several
+	// equally: br, zstd and gzip.
+	Encodings []string `yaml:"encodings" env:"COMPRESSION_ENCODINGS" default:"br,zstd,gzip" validate:"min=1,dive,oneof=br zstd gzip"`
+	Level     string   `yaml:"level" env:"COMPRESSION_LEVEL" default:"default" validate:"oneof=fastest default best"`
+	// MinSize in bytes, smaller responses are sent as they are.
+	MinSize      int      `yaml:"minSize" env:"COMPRESSION_MIN_SIZE" default:"1024" validate:"min=0"`
+	ContentTypes []string `yaml:"contentTypes" env:"COMPRESSION_CONTENT_TYPES" default:"application/json,application/xml,application/msgpack,application/x-protobuf,text/csv,text/plain,text/html"`
+}
+
+// DeprecatedAt returns the date version was deprecated, if it is.
+func (c VersioningConfig) DeprecatedAt(version string) (time.Time, bool) {
+	return versionDate(c.Deprecated, version)
+}
+
+// SunsetAt returns the date version stops being served, if it is set.
+func (c VersioningConfig) SunsetAt(version string) (time.Time, bool) {
+	return versionDate(c.Sunset, version)
+}
+
+func versionDate(entries []string, version string) (time.Time, bool) {
+	for _, entry := range entries {
+		name, date, _ := strings.Cut(entry, "=")
+		if name != version {
+			continue
+		}
+		t, err := time.Parse(time.DateOnly, date)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
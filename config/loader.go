@@ -0,0 +1,220 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const redactedValue = "******"
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	logLevelRule = regexp.MustCompile(`^[A-Za-z0-9_./-]+=(?i:debug|info|warn|error)$`)
+	versionRule  = regexp.MustCompile(`^v[0-9]+$`)
+)
+
+// Load reads the configuration from defaults, the optional YAML file in
+// CONFIG_FILE, the .env file and the environment, then validates it. All
+// problems are reported together in a single error.
+func Load() (cfg Config, err error) {
+	godotenv.Load()
+
+	var errs []string
+	walkFields(reflect.ValueOf(&cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if setErr := setValue(value, def); setErr != nil {
+				errs = append(errs, fmt.Sprintf("default %s: %v", fieldName(field), setErr))
+			}
+		}
+	})
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		content, readErr := os.ReadFile(path)
+		if readErr != nil {
+			err = fmt.Errorf("invalid configuration:\n  - CONFIG_FILE: %v", readErr)
+			return
+		}
+
+		if yamlErr := yaml.Unmarshal(content, &cfg); yamlErr != nil {
+			err = fmt.Errorf("invalid configuration:\n  - CONFIG_FILE %s: %v", path, yamlErr)
+			return
+		}
+	}
+
+	walkFields(reflect.ValueOf(&cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		name, ok := field.Tag.Lookup("env")
+		if !ok {
+			return
+		}
+
+		raw, ok := os.LookupEnv(name)
+		if !ok || raw == "" {
+			return
+		}
+
+		if setErr := setValue(value, raw); setErr != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, setErr))
+		}
+	})
+
+	errs = append(errs, validate(cfg)...)
+	if len(errs) > 0 {
+		err = errors.New("invalid configuration:\n  - " + strings.Join(errs, "\n  - "))
+	}
+	return
+}
+
+func MustLoad() Config {
+	cfg, err := Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// Redacted returns the effective configuration keyed by environment variable
+// name with secret values masked, suitable for logging at startup.
+func (c Config) Redacted() map[string]interface{} {
+	result := make(map[string]interface{})
+	walkFields(reflect.ValueOf(c), func(field reflect.StructField, value reflect.Value) {
+		name := fieldName(field)
+		if field.Tag.Get("secret") == "true" {
+			if !value.IsZero() {
+				result[name] = redactedValue
+			} else {
+				result[name] = ""
+			}
+			return
+		}
+
+		if value.Type() == durationType {
+			result[name] = value.Interface().(time.Duration).String()
+			return
+		}
+		result[name] = value.Interface()
+	})
+	return result
+}
+
+func validate(cfg Config) (errs []string) {
+	v := validator.New()
+	v.RegisterTagNameFunc(fieldName)
+	v.RegisterValidation("regexp", func(fl validator.FieldLevel) bool {
+		_, err := regexp.Compile(fl.Field().String())
+		return err == nil
+	})
+	v.RegisterValidation("loglevel", func(fl validator.FieldLevel) bool {
+		return logLevelRule.MatchString(fl.Field().String())
+	})
+	v.RegisterValidation("versiondate", func(fl validator.FieldLevel) bool {
+		version, date, ok := strings.Cut(fl.Field().String(), "=")
+		if !ok || !versionRule.MatchString(version) {
+			return false
+		}
+		_, err := time.Parse(time.DateOnly, date)
+		return err == nil
+	})
+
+	err := v.Struct(cfg)
+	if err == nil {
+		return
+	}
+
+	validationErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []string{err.Error()}
+	}
+
+	for _, fieldErr := range validationErrs {
+		rule := fieldErr.Tag()
+		if fieldErr.Param() != "" {
+			rule += "=" + fieldErr.Param()
+		}
+		errs = append(errs, fmt.Sprintf("%s: failed on %q rule (value %v)", fieldErr.Field(), rule, fieldErr.Value()))
+	}
+	return
+}
+
+func walkFields(v reflect.Value, fn func(field reflect.StructField, value reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+			walkFields(value, fn)
+			continue
+		}
+		fn(field, value)
+	}
+}
+
+func fieldName(field reflect.StructField) string {
+	if name := field.Tag.Get("env"); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+func setValue(value reflect.Value, raw string) (err error) {
+	if value.Type() == durationType {
+		var d time.Duration
+		d, err = time.ParseDuration(raw)
+		if err == nil {
+			value.SetInt(int64(d))
+		}
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(raw)
+		if err == nil {
+			value.SetBool(b)
+		}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(raw, 10, 64)
+		if err == nil {
+			value.SetInt(i)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(raw, 64)
+		if err == nil {
+			value.SetFloat(f)
+		}
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", value.Type())
+		}
+
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		err = fmt.Errorf("unsupported type %s", value.Type())
+	}
+
+	if err != nil {
+		err = fmt.Errorf("cannot parse %q as %s", raw, value.Type())
+	}
+	return
+}
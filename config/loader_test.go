@@ -0,0 +1,170 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	requiredEnv := map[string]string{
+		"DB_USERNAME": "root",
+		"DB_HOST":     "localhost",
+		"DB_NAME":     "simple_api",
+	}
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		yamlContent string
+		wantErr     []string
+		check       func(t *testing.T, cfg Config)
+	}{
+		{
+			name: "apply defaults",
+			env:  requiredEnv,
+			check: func(t *testing.T, cfg Config) {
+				if cfg.App.Port != 7690 {
+					t.Errorf("Load() App.Port = %v, want 7690", cfg.App.Port)
+				}
+				if cfg.App.ShutdownTimeout != 15*time.Second {
+					t.Errorf("Load() App.ShutdownTimeout = %v, want 15s", cfg.App.ShutdownTimeout)
+				}
+				if len(cfg.RequestID.InboundHeaders) != 2 || !cfg.RequestID.TrustInbound {
+					t.Errorf("Load() RequestID = %+v, want default headers and trusted inbound", cfg.RequestID)
+				}
+				if cfg.RequestID.ResponseHeader != "X-Request-ID" {
+					t.Errorf("Load() RequestID.ResponseHeader = %v, want X-Request-ID", cfg.RequestID.ResponseHeader)
+				}
+			},
+		},
+		{
+			name: "environment overrides yaml file",
+			env: map[string]string{
+				"DB_USERNAME": "root",
+				"DB_NAME":     "simple_api",
+				"APP_PORT":    "9000",
+			},
+			yamlContent: "app:\n  port: 8000\n  shutdownTimeout: 30s\ndatabase:\n  host: mysql\n",
+			check: func(t *testing.T, cfg Config) {
+				if cfg.App.Port != 9000 {
+					t.Errorf("Load() App.Port = %v, want 9000", cfg.App.Port)
+				}
+				if cfg.App.ShutdownTimeout != 30*time.Second {
+					t.Errorf("Load() App.ShutdownTimeout = %v, want 30s", cfg.App.ShutdownTimeout)
+				}
+				if cfg.Database.Host != "mysql" {
+					t.Errorf("Load() Database.Host = %v, want mysql", cfg.Database.Host)
+				}
+			},
+		},
+		{
+			name: "report every invalid value",
+			env: map[string]string{
+				"APP_PORT":             "70000",
+				"DB_PORT":              "abc",
+				"TRACING_EXPORTER":     "jaeger",
+				"TRACING_SAMPLE_RATIO": "2",
+				"IDEMPOTENCY_STORE":    "redis",
+			},
+			wantErr: []string{"APP_PORT", "DB_PORT", "DB_USERNAME", "DB_HOST", "DB_NAME", "TRACING_EXPORTER", "TRACING_SAMPLE_RATIO", "IDEMPOTENCY_STORE"},
+		},
+		{
+			name: "reject unknown and invalid redaction patterns",
+			env: map[string]string{
+				"DB_USERNAME":         "root",
+				"DB_HOST":             "localhost",
+				"DB_NAME":             "simple_api",
+				"LOG_REDACT_PATTERNS": "email,ssn",
+			},
+			yamlContent: "redaction:\n  customPatterns: ['sk_[a-z]+', '(unclosed']\n",
+			wantErr:     []string{"LOG_REDACT_PATTERNS[1]", "CustomPatterns[1]"},
+		},
+		{
+			name: "reject invalid logger levels",
+			env: map[string]string{
+				"DB_USERNAME":   "root",
+				"DB_HOST":       "localhost",
+				"DB_NAME":       "simple_api",
+				"LOGGER_LEVEL":  "trace",
+				"LOGGER_LEVELS": "usecase=DEBUG,rpc",
+			},
+			wantErr: []string{"LOGGER_LEVEL", "LOGGER_LEVELS[1]"},
+		},
+		{
+			name: "reject wildcard credential origins",
+			env: map[string]string{
+				"DB_USERNAME":             "root",
+				"DB_HOST":                 "localhost",
+				"DB_NAME":                 "simple_api",
+				"CORS_CREDENTIAL_ORIGINS": "https://admin.example.com,https://*.example.com",
+			},
+			wantErr: []string{"CORS_CREDENTIAL_ORIGINS[1]"},
+		},
+		{
+			name: "reject invalid version dates",
+			env: map[string]string{
+				"DB_USERNAME":             "root",
+				"DB_HOST":                 "localhost",
+				"DB_NAME":                 "simple_api",
+				"API_DEPRECATED_VERSIONS": "v1=2026-01-01",
+				"API_SUNSET_VERSIONS":     "v1=30/06/2027",
+			},
+			wantErr: []string{"API_SUNSET_VERSIONS[0]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", "")
+			walkFields(reflect.ValueOf(&Config{}).Elem(), func(field reflect.StructField, value reflect.Value) {
+				if name := field.Tag.Get("env"); name != "" {
+					t.Setenv(name, "")
+				}
+			})
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			if tt.yamlContent != "" {
+				path := filepath.Join(t.TempDir(), "config.yaml")
+				os.WriteFile(path, []byte(tt.yamlContent), 0644)
+				t.Setenv("CONFIG_FILE", path)
+			}
+
+			cfg, err := Load()
+			if (err != nil) != (len(tt.wantErr) > 0) {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for _, name := range tt.wantErr {
+				if !strings.Contains(err.Error(), name) {
+					t.Errorf("Load() error = %v, want it to mention %v", err, name)
+				}
+			}
+
+			if tt.check != nil {
+				tt.check(t, cfg)
+			}
+		})
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := Config{
+		Database: DatabaseConfig{
+			Username: "root",
+			Password: "s3cret",
+		},
+	}
+
+	redacted := cfg.Redacted()
+	if redacted["DB_PASSWORD"] != redactedValue {
+		t.Errorf("Config.Redacted() DB_PASSWORD = %v, want %v", redacted["DB_PASSWORD"], redactedValue)
+	}
+	if redacted["DB_USERNAME"] != "root" {
+		t.Errorf("Config.Redacted() DB_USERNAME = %v, want root", redacted["DB_USERNAME"])
+	}
+}
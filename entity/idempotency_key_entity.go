@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"net/http"
+	"time"
+)
+
+type IdempotencyKey struct {
+	KeyHash     string `gorm:"primarykey;size:64"`
+	Fingerprint string
+	Completed   bool
+	StatusCode  int
+	Header      http.Header `gorm:"serializer:json"`
+	Body        []byte
+	ExpiresAt   time.Time `gorm:"index"`
+}
@@ -1,47 +1,253 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
-	"os"
+	"net"
+	"net/http"
+	"time"
 
+	"github.com/fadilahonespot/simple-api/config"
 	"github.com/fadilahonespot/simple-api/repository"
+	"github.com/fadilahonespot/simple-api/server/gql"
 	"github.com/fadilahonespot/simple-api/server/handler"
 	"github.com/fadilahonespot/simple-api/server/router"
+	"github.com/fadilahonespot/simple-api/server/rpc"
+	"github.com/fadilahonespot/simple-api/server/ws"
 	"github.com/fadilahonespot/simple-api/usecase"
+	"github.com/fadilahonespot/simple-api/utils/certs"
 	"github.com/fadilahonespot/simple-api/utils/database"
+	"github.com/fadilahonespot/simple-api/utils/events"
+	"github.com/fadilahonespot/simple-api/utils/health"
+	"github.com/fadilahonespot/simple-api/utils/idempotency"
+	"github.com/fadilahonespot/simple-api/utils/lifecycle"
 	"github.com/fadilahonespot/simple-api/utils/logger"
-	"github.com/joho/godotenv"
+	"github.com/fadilahonespot/simple-api/utils/metrics"
+	"github.com/fadilahonespot/simple-api/utils/tracing"
 	"github.com/labstack/echo/v4"
+	"golang.org/x/net/http2"
 )
 
 func main() {
-	// Load Env
-	godotenv.Load()
-	
-	// Setup database
-	db := database.InitDB()
+	// Load config
+	cfg := config.MustLoad()
 
 	// Setup logger
-	logger.NewLogger()
+	logger.NewLogger(cfg.Logger)
+	logger.Info(context.Background(), "Effective Config", cfg.Redacted())
+
+	// Setup lifecycle
+	app := lifecycle.NewLifecycle(cfg.App.ShutdownTimeout, logger.Named("lifecycle"))
+
+	// Setup tracing
+	shutdownTracer, err := tracing.NewTracerProvider(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatal(err)
+	}
+	app.Append(lifecycle.Hook{
+		Name:   "tracing",
+		OnStop: shutdownTracer,
+	})
+
+	// Setup database
+	db, replicas := database.InitDB(cfg.Database, logger.Named("database"))
+	err = tracing.RegisterGormCallbacks(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	app.Append(lifecycle.Hook{
+		Name: "database",
+		OnStop: func(ctx context.Context) error {
+			return database.CloseDB(db, replicas)
+		},
+	})
+
+	// Setup metrics
+	err = metrics.RegisterGormCallbacks(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = metrics.RegisterDBStats(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Setup health checks
+	healthRegistry := health.NewRegistry(cfg.Health.CheckTimeout)
+	healthRegistry.Register("database", func(ctx context.Context) error {
+		return database.Ping(ctx, db)
+	})
+	healthRegistry.Register("migration", func(ctx context.Context) error {
+		return database.CheckMigration(ctx, db)
+	})
+	for _, replica := range replicas {
+		healthRegistry.Register("database-replica-"+replica.Host, replica.DB.PingContext)
+	}
 
 	// Setup repository
-	productRepo := repository.NewProductRepository(db)
+	productRepo := repository.NewProductRepository(db, logger.Named("repository"))
+
+	txIsolation, err := database.ParseIsolation(cfg.Database.TxIsolation)
+	if err != nil {
+		log.Fatal(err)
+	}
+	txManager := repository.NewTxManager(db, txIsolation)
+
+	// Setup events
+	broker := events.NewBroker(cfg.Events.ReplayBuffer, cfg.Events.ClientBuffer)
 
 	// Setup usecase
-	productUsecase := usecase.NewProductRepository(productRepo)
+	productUsecase := usecase.NewInstrumentedProductUsecase(usecase.NewProductRepository(productRepo, txManager, broker, logger.Named("usecase")))
 
 	// Set handler
-	productHandler := handler.NewProductHandler(productUsecase)
+	productHandler := handler.NewProductHandler(productUsecase, logger.Named("handler"))
+	productV2Handler := handler.NewProductV2Handler(productUsecase, logger.Named("handler"))
+	healthHandler := handler.NewHealthHandler(healthRegistry, logger.Named("handler"))
+	productEventHandler := handler.NewProductEventHandler(broker, cfg.Events.Heartbeat, logger.Named("handler"))
+	logLevelHandler := handler.NewLogLevelHandler(logger.GetLevels(), logger.Named("handler"))
+	if len(cfg.Admin.Tokens) == 0 {
+		logger.Info(context.Background(), "ADMIN_TOKENS is empty, every /admin request will be refused")
+	}
+
+	var webSocketHandler *handler.WebSocketHandler
+	if cfg.WebSocket.Enabled {
+		if len(cfg.WebSocket.Tokens) == 0 {
+			logger.Info(context.Background(), "WEBSOCKET_TOKENS is empty, every WebSocket connection will be refused")
+		}
+		h := handler.NewWebSocketHandler(ws.NewHub(cfg.WebSocket, broker, logger.Named("websocket")), logger.Named("handler"))
+		webSocketHandler = &h
+	}
+
+	var graphQLHandler *handler.GraphQLHandler
+	if cfg.GraphQL.Enabled {
+		executor, err := gql.NewExecutor(cfg.GraphQL, productUsecase)
+		if err != nil {
+			log.Fatal(err)
+		}
+		h := handler.NewGraphQLHandler(executor, logger.Named("handler"))
+		graphQLHandler = &h
+	}
 
 	// Set Router
 	e := echo.New()
+	e.HideBanner = true
+	e.Server.ReadHeaderTimeout = cfg.App.ReadHeaderTimeout
+	e.Server.ReadTimeout = cfg.App.ReadTimeout
+	e.Server.WriteTimeout = cfg.App.WriteTimeout
+	e.Server.IdleTimeout = cfg.App.IdleTimeout
 	router := router.DefaultRouter{
-		ProductHandler: &productHandler,
+		Config:           cfg,
+		ProductHandler:   &productHandler,
+		ProductV2Handler: &productV2Handler,
+		HealthHandler:    &healthHandler,
+		GraphQLHandler:   graphQLHandler,
+
+		ProductEventHandler: &productEventHandler,
+		WebSocketHandler:    webSocketHandler,
+		LogLevelHandler:     &logLevelHandler,
+		IdempotencyStore:    idempotency.NewStore(cfg.Idempotency, db),
+		Logger:              logger.Named("http"),
 	}
 	router.NewRouter(e).Validate()
-	err := e.Start(fmt.Sprintf(":%v", os.Getenv("APP_PORT")))
+
+	// Set TLS certificates, reloaded when the files change
+	var certReloader certs.Reloader
+	if cfg.TLS.Enabled() {
+		certReloader, err = certs.NewReloader(cfg.TLS, logger.Named("tls"))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		watchCtx, stopWatch := context.WithCancel(context.Background())
+		app.Append(lifecycle.Hook{
+			Name: "tls-reload",
+			OnStart: func(ctx context.Context) error {
+				go certReloader.Watch(watchCtx)
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				stopWatch()
+				return nil
+			},
+		})
+	}
+
+	// Set HTTP server
+	app.Append(lifecycle.Hook{
+		Name: "http-server",
+		OnStart: func(ctx context.Context) error {
+			address := fmt.Sprintf(":%d", cfg.App.Port)
+			go func() {
+				var err error
+				switch {
+				case certReloader != nil:
+					e.Server.Addr = address
+					e.Server.TLSConfig = certReloader.TLSConfig()
+					err = e.StartServer(e.Server)
+				case cfg.TLS.H2C:
+					err = e.StartH2CServer(address, &http2.Server{IdleTimeout: cfg.App.IdleTimeout})
+				default:
+					err = e.Start(address)
+				}
+				if err != nil && !errors.Is(err, http.ErrServerClosed) {
+					app.Fail(err)
+				}
+			}()
+			return nil
+		},
+		OnStop: e.Shutdown,
+	})
+
+	// Set gRPC server
+	if cfg.GRPC.Enabled {
+		grpcServer := rpc.NewServer(cfg, productUsecase, logger.Named("grpc"))
+		app.Append(lifecycle.Hook{
+			Name: "grpc-server",
+			OnStart: func(ctx context.Context) error {
+				listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+				if err != nil {
+					return err
+				}
+
+				go func() {
+					err := grpcServer.Serve(listener)
+					if err != nil {
+						app.Fail(err)
+					}
+				}()
+				return nil
+			},
+			OnStop: grpcServer.Shutdown,
+		})
+	}
+
+	// Close event streams, they never go idle and would hold up the HTTP shutdown
+	app.Append(lifecycle.Hook{
+		Name: "events",
+		OnStop: func(ctx context.Context) error {
+			broker.Close()
+			return nil
+		},
+	})
+
+	// Fail readiness before draining so the orchestrator stops routing traffic
+	app.Append(lifecycle.Hook{
+		Name: "readiness",
+		OnStop: func(ctx context.Context) error {
+			healthRegistry.Shutdown()
+			select {
+			case <-time.After(cfg.Health.ShutdownDelay):
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+
+	err = app.Run()
 	if err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+}
@@ -44,32 +44,39 @@ func (_m *ProductRepository) DeleteProduct(ctx context.Context, id string) error
 	return r0
 }
 
-// GetListProduct provides a mock function with given fields: ctx, param
-func (_m *ProductRepository) GetListProduct(ctx context.Context, param paginate.Pagination) ([]entity.Product, int64, error) {
-	ret := _m.Called(ctx, param)
+// GetListProduct provides a mock function with given fields: ctx, param, columns
+func (_m *ProductRepository) GetListProduct(ctx context.Context, param paginate.Pagination, columns ...string) ([]entity.Product, int64, error) {
+	_va := make([]interface{}, len(columns))
+	for _i := range columns {
+		_va[_i] = columns[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, param)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	var r0 []entity.Product
 	var r1 int64
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, paginate.Pagination) ([]entity.Product, int64, error)); ok {
-		return rf(ctx, param)
+	if rf, ok := ret.Get(0).(func(context.Context, paginate.Pagination, ...string) ([]entity.Product, int64, error)); ok {
+		return rf(ctx, param, columns...)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, paginate.Pagination) []entity.Product); ok {
-		r0 = rf(ctx, param)
+	if rf, ok := ret.Get(0).(func(context.Context, paginate.Pagination, ...string) []entity.Product); ok {
+		r0 = rf(ctx, param, columns...)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]entity.Product)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, paginate.Pagination) int64); ok {
-		r1 = rf(ctx, param)
+	if rf, ok := ret.Get(1).(func(context.Context, paginate.Pagination, ...string) int64); ok {
+		r1 = rf(ctx, param, columns...)
 	} else {
 		r1 = ret.Get(1).(int64)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, paginate.Pagination) error); ok {
-		r2 = rf(ctx, param)
+	if rf, ok := ret.Get(2).(func(context.Context, paginate.Pagination, ...string) error); ok {
+		r2 = rf(ctx, param, columns...)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -77,25 +84,65 @@ func (_m *ProductRepository) GetListProduct(ctx context.Context, param paginate.
 	return r0, r1, r2
 }
 
-// GetProductById provides a mock function with given fields: ctx, id
-func (_m *ProductRepository) GetProductById(ctx context.Context, id string) (*entity.Product, error) {
-	ret := _m.Called(ctx, id)
+// GetProductById provides a mock function with given fields: ctx, id, columns
+func (_m *ProductRepository) GetProductById(ctx context.Context, id string, columns ...string) (*entity.Product, error) {
+	_va := make([]interface{}, len(columns))
+	for _i := range columns {
+		_va[_i] = columns[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, id)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	var r0 *entity.Product
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Product, error)); ok {
-		return rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) (*entity.Product, error)); ok {
+		return rf(ctx, id, columns...)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Product); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) *entity.Product); ok {
+		r0 = rf(ctx, id, columns...)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*entity.Product)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, id, columns...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProductsByIds provides a mock function with given fields: ctx, ids, columns
+func (_m *ProductRepository) GetProductsByIds(ctx context.Context, ids []string, columns ...string) ([]entity.Product, error) {
+	_va := make([]interface{}, len(columns))
+	for _i := range columns {
+		_va[_i] = columns[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, ids)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 []entity.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, ...string) ([]entity.Product, error)); ok {
+		return rf(ctx, ids, columns...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, ...string) []entity.Product); ok {
+		r0 = rf(ctx, ids, columns...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, ...string) error); ok {
+		r1 = rf(ctx, ids, columns...)
 	} else {
 		r1 = ret.Error(1)
 	}
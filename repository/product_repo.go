@@ -2,15 +2,25 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/fadilahonespot/simple-api/entity"
+	"github.com/fadilahonespot/simple-api/utils/database"
+	"github.com/fadilahonespot/simple-api/utils/logger"
 	"github.com/fadilahonespot/simple-api/utils/paginate"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrDuplicateProduct = errors.New("product title already exists")
+
 type ProductRepository interface {
-	GetListProduct(ctx context.Context, param paginate.Pagination) (resp []entity.Product, count int64, err error)
-	GetProductById(ctx context.Context, id string) (resp *entity.Product, err error)
+	// GetListProduct and GetProductById read only columns, every column when
+	// none are given.
+	GetListProduct(ctx context.Context, param paginate.Pagination, columns ...string) (resp []entity.Product, count int64, err error)
+	GetProductById(ctx context.Context, id string, columns ...string) (resp *entity.Product, err error)
+	// GetProductsByIds reads the products of ids that exist, in any order.
+	GetProductsByIds(ctx context.Context, ids []string, columns ...string) (resp []entity.Product, err error)
 	GetProductByTitle(ctx context.Context, title string) (resp *entity.Product, err error)
 	CreateProduct(ctx context.Context, req *entity.Product) (err error)
 	UpdateProduct(ctx context.Context, req *entity.Product) (err error)
@@ -18,15 +28,16 @@ type ProductRepository interface {
 }
 
 type defaultProductRepo struct {
-	db *gorm.DB
-} 
+	db  *gorm.DB
+	log logger.Logger
+}
 
-func NewProductRepository(db *gorm.DB) ProductRepository {
-	return &defaultProductRepo{db: db}
+func NewProductRepository(db *gorm.DB, log logger.Logger) ProductRepository {
+	return &defaultProductRepo{db: db, log: log}
 }
 
-func (s *defaultProductRepo) GetListProduct(ctx context.Context, param paginate.Pagination) (resp []entity.Product, count int64, err error) {
-	query := func (db *gorm.DB) *gorm.DB  {
+func (s *defaultProductRepo) GetListProduct(ctx context.Context, param paginate.Pagination, columns ...string) (resp []entity.Product, count int64, err error) {
+	query := func(db *gorm.DB) *gorm.DB {
 		if param.Title != "" {
 			db.Where("title LIKE ?", "%"+param.Title+"%")
 		}
@@ -37,37 +48,82 @@ func (s *defaultProductRepo) GetListProduct(ctx context.Context, param paginate.
 		return db
 	}
 
-	err = s.db.WithContext(ctx).Model(&entity.Product{}).Scopes(query).Count(&count).Error
+	err = database.Conn(ctx, s.db).Model(&entity.Product{}).Scopes(query).Count(&count).Error
 	if err != nil {
+		s.log.Debug(ctx, "error counting products", err.Error())
 		return
 	}
 
-	err = s.db.WithContext(ctx).Scopes(paginate.Paginate(param.Page, param.Limit)).Scopes(query).Find(&resp).Error
+	pagination := paginate.Paginate(param.Page, param.Limit)
+	if param.Offset > 0 {
+		pagination = paginate.PaginateOffset(param.Offset, param.Limit)
+	}
+
+	err = database.Conn(ctx, s.db).Scopes(pagination, selectColumns(columns)).Scopes(query).Find(&resp).Error
+	if err != nil {
+		s.log.Debug(ctx, "error finding products", err.Error())
+	}
+	return
+}
+
+func (s *defaultProductRepo) GetProductById(ctx context.Context, id string, columns ...string) (resp *entity.Product, err error) {
+	db := database.Conn(ctx, s.db).Scopes(selectColumns(columns))
+	if database.InTransaction(ctx) {
+		db = db.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	err = db.Take(&resp, "id = ?", id).Error
 	return
 }
 
-func (s *defaultProductRepo) GetProductById(ctx context.Context, id string) (resp *entity.Product, err error) {
-	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+func (s *defaultProductRepo) GetProductsByIds(ctx context.Context, ids []string, columns ...string) (resp []entity.Product, err error) {
+	err = database.Conn(ctx, s.db).Scopes(selectColumns(columns)).Find(&resp, "id IN ?", ids).Error
+	if err != nil {
+		s.log.Debug(ctx, "error finding products by ids", err.Error())
+	}
 	return
 }
 
 func (s *defaultProductRepo) GetProductByTitle(ctx context.Context, title string) (resp *entity.Product, err error) {
-	err = s.db.WithContext(ctx).Take(&resp, "LOWER(title) = LOWER(?)", title).Error
+	err = database.Conn(ctx, s.db).Take(&resp, "LOWER(title) = LOWER(?)", title).Error
 	return
 }
 
-
 func (s *defaultProductRepo) CreateProduct(ctx context.Context, req *entity.Product) (err error) {
-	err = s.db.WithContext(ctx).Create(req).Error
+	err = database.Conn(ctx, s.db).Create(req).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		err = ErrDuplicateProduct
+	}
+	if err != nil {
+		s.log.Debug(ctx, "error creating product", err.Error())
+	}
 	return
 }
 
 func (s *defaultProductRepo) UpdateProduct(ctx context.Context, req *entity.Product) (err error) {
-	err = s.db.WithContext(ctx).Save(req).Error
+	err = database.Conn(ctx, s.db).Save(req).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		err = ErrDuplicateProduct
+	}
+	if err != nil {
+		s.log.Debug(ctx, "error saving product", err.Error())
+	}
 	return
 }
 
 func (s *defaultProductRepo) DeleteProduct(ctx context.Context, id string) (err error) {
-	err = s.db.WithContext(ctx).Delete(&entity.Product{}, "id = ?", id).Error
+	err = database.Conn(ctx, s.db).Delete(&entity.Product{}, "id = ?", id).Error
+	if err != nil {
+		s.log.Debug(ctx, "error deleting product", err.Error())
+	}
 	return
-}
\ No newline at end of file
+}
+
+func selectColumns(columns []string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(columns) == 0 {
+			return db
+		}
+		return db.Select(columns)
+	}
+}
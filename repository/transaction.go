@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fadilahonespot/simple-api/utils/database"
+	"gorm.io/gorm"
+)
+
+type TxManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error)
+	WithinTransactionOptions(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) (err error)
+}
+
+type defaultTxManager struct {
+	db        *gorm.DB
+	isolation sql.IsolationLevel
+}
+
+func NewTxManager(db *gorm.DB, isolation sql.IsolationLevel) TxManager {
+	return &defaultTxManager{db: db, isolation: isolation}
+}
+
+func (s *defaultTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	return s.WithinTransactionOptions(ctx, &sql.TxOptions{Isolation: s.isolation}, fn)
+}
+
+// WithinTransactionOptions runs fn in a transaction carried by the context
+// passed to it, so every repository call made with that context joins it. The
+// transaction is rolled back when fn returns an error or panics. Nested calls
+// create a savepoint in the outer transaction and ignore opts.
+func (s *defaultTxManager) WithinTransactionOptions(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) (err error) {
+	if tx, ok := database.TxFromContext(ctx); ok {
+		return tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(database.WithTx(ctx, tx))
+		})
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(database.WithTx(ctx, tx))
+	}, opts)
+}
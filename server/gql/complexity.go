@@ -0,0 +1,108 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// listFields are fields whose selection is repeated once per item; their cost
+// is multiplied by the `limit` argument.
+var listFields = map[string]bool{
+	"products": true,
+}
+
+type complexityAnalysis struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	maxDepth  int
+}
+
+// checkComplexity rejects operations deeper than maxDepth or costing more
+// than maxComplexity. Every field costs 1, the selection of a list field costs
+// its children times the requested limit. Introspection fields are free.
+func checkComplexity(doc *ast.Document, operationName string, variables map[string]interface{}, maxDepth, maxComplexity int) error {
+	analysis := &complexityAnalysis{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			analysis.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+
+	complexity := analysis.selectionSet(operation.SelectionSet, 1)
+	if analysis.maxDepth > maxDepth {
+		return fmt.Errorf("query depth %d exceeds the maximum of %d", analysis.maxDepth, maxDepth)
+	}
+	if complexity > maxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the maximum of %d", complexity, maxComplexity)
+	}
+	return nil
+}
+
+func (a *complexityAnalysis) selectionSet(selectionSet *ast.SelectionSet, depth int) (complexity int) {
+	if selectionSet == nil {
+		return 0
+	}
+
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			if depth > a.maxDepth {
+				a.maxDepth = depth
+			}
+
+			children := a.selectionSet(selection.SelectionSet, depth+1)
+			if listFields[selection.Name.Value] {
+				children *= a.limit(selection)
+			}
+			complexity += 1 + children
+		case *ast.InlineFragment:
+			complexity += a.selectionSet(selection.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			if fragment, ok := a.fragments[selection.Name.Value]; ok {
+				complexity += a.selectionSet(fragment.SelectionSet, depth)
+			}
+		}
+	}
+	return
+}
+
+func (a *complexityAnalysis) limit(field *ast.Field) int {
+	limit := defaultLimit
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "limit" {
+			continue
+		}
+
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			limit, _ = strconv.Atoi(value.Value)
+		case *ast.Variable:
+			switch variable := a.variables[value.Name.Value].(type) {
+			case float64:
+				limit = int(variable)
+			case int:
+				limit = variable
+			}
+		}
+	}
+	return clamp(limit, 1, maxLimit)
+}
@@ -0,0 +1,52 @@
+package gql
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fadilahonespot/library/errors"
+)
+
+// resolverError exposes the status of a usecase error in the GraphQL error
+// extensions.
+type resolverError struct {
+	status  int
+	message string
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+func (e *resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   errorCode(e.status),
+		"status": e.status,
+	}
+}
+
+func newResolverError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if appErr, ok := err.(*errors.ApplicationError); ok {
+		return &resolverError{status: appErr.ErrorCode, message: appErr.Error()}
+	}
+	return &resolverError{status: http.StatusInternalServerError, message: http.StatusText(http.StatusInternalServerError)}
+}
+
+func errorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return "BAD_USER_INPUT"
+	case http.StatusInternalServerError:
+		return "INTERNAL_SERVER_ERROR"
+	}
+
+	text := http.StatusText(status)
+	if text == "" {
+		return "INTERNAL_SERVER_ERROR"
+	}
+	return strings.ToUpper(strings.ReplaceAll(text, " ", "_"))
+}
@@ -0,0 +1,93 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/usecase"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+type Request struct {
+	Query         string                 `json:"query" validate:"required"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+type Response struct {
+	Data   interface{}                `json:"data,omitempty"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+type Executor interface {
+	Execute(ctx context.Context, req Request) Response
+}
+
+type defaultExecutor struct {
+	cfg            config.GraphQLConfig
+	schema         graphql.Schema
+	productUsecase usecase.ProductUsecase
+}
+
+func NewExecutor(cfg config.GraphQLConfig, productUsecase usecase.ProductUsecase) (Executor, error) {
+	schema, err := newSchema(productUsecase)
+	if err != nil {
+		return nil, err
+	}
+
+	return &defaultExecutor{cfg: cfg, schema: schema, productUsecase: productUsecase}, nil
+}
+
+func (e *defaultExecutor) Execute(ctx context.Context, req Request) Response {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return Response{Errors: []gqlerrors.FormattedError{gqlerrors.FormatError(err)}}
+	}
+
+	validation := graphql.ValidateDocument(&e.schema, doc, nil)
+	if !validation.IsValid {
+		return Response{Errors: validation.Errors}
+	}
+
+	err = checkComplexity(doc, req.OperationName, req.Variables, e.cfg.MaxDepth, e.cfg.MaxComplexity)
+	if err != nil {
+		return Response{Errors: []gqlerrors.FormattedError{gqlerrors.FormatError(err)}}
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        e.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoader(ctx, newProductLoader(e.productUsecase)),
+	})
+	for i := range result.Errors {
+		if result.Errors[i].Extensions == nil {
+			result.Errors[i].Extensions = extensionsOf(result.Errors[i].OriginalError())
+		}
+	}
+	return Response{Data: result.Data, Errors: result.Errors}
+}
+
+// extensionsOf finds the extensions of an error raised by a thunk, which
+// graphql-go wraps without copying them.
+func extensionsOf(err error) map[string]interface{} {
+	for err != nil {
+		switch e := err.(type) {
+		case gqlerrors.ExtendedError:
+			return e.Extensions()
+		case *gqlerrors.Error:
+			err = e.OriginalError
+		case gqlerrors.FormattedError:
+			err = e.OriginalError()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	libErrors "github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/usecase/dto"
+	"github.com/fadilahonespot/simple-api/usecase/mocks"
+	"github.com/fadilahonespot/simple-api/utils/fieldset"
+	"github.com/fadilahonespot/simple-api/utils/paginate"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	testConfig = config.GraphQLConfig{Enabled: true, MaxDepth: 8, MaxComplexity: 300}
+	withAudit  = fieldset.Fieldset{Expand: []string{dto.SectionAudit}}
+)
+
+func newTestExecutor(t *testing.T, cfg config.GraphQLConfig, productUsecase *mocks.ProductUsecase) Executor {
+	executor, err := NewExecutor(cfg, productUsecase)
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+	return executor
+}
+
+func Test_defaultExecutor_Execute_products(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	createdAt := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	productUsecase := mocks.NewProductUsecase(t)
+	productUsecase.On("GetListProduct", mock.Anything, paginate.Pagination{Page: 2, Limit: 2, Title: "Mie"}, withAudit).Return([]dto.ProductListResponse{
+		{ID: first, Title: "Mie Goreng", Audit: &dto.Audit{CreatedAt: createdAt}},
+		{ID: second, Title: "Mie Kuah", Audit: &dto.Audit{CreatedAt: createdAt}},
+	}, int64(5), nil).Once()
+
+	resp := newTestExecutor(t, testConfig, productUsecase).Execute(context.Background(), Request{
+		Query: `query($limit: Int) {
+			products(filter: {title: "Mie"}, page: 2, limit: $limit) {
+				totalCount
+				nodes { id title createdAt }
+				edges { node { createdAt } }
+				pageInfo { page totalPage hasNextPage hasPreviousPage }
+			}
+		}`,
+		Variables: map[string]interface{}{"limit": 2},
+	})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Execute() errors = %v", resp.Errors)
+	}
+
+	body, _ := json.Marshal(resp.Data)
+	for _, want := range []string{
+		`"totalCount":5`,
+		`"title":"Mie Goreng"`,
+		`"createdAt":"2023-12-01T00:00:00Z"`,
+		`"pageInfo":{"hasNextPage":true,"hasPreviousPage":true,"page":2,"totalPage":3}`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Execute() data = %s, want it to contain %s", body, want)
+		}
+	}
+}
+
+func Test_defaultExecutor_Execute_productsCursor(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       string
+		wantParams paginate.Pagination
+		wantInfo   string
+	}{
+		{
+			name:       "after",
+			args:       `after: "` + cursor(&productNode{offset: 3}) + `"`,
+			wantParams: paginate.Pagination{Page: 1, Limit: 2, Offset: 4},
+			wantInfo:   `"pageInfo":{"endCursor":"` + cursor(&productNode{offset: 5}) + `","hasNextPage":true,"hasPreviousPage":true,"startCursor":"` + cursor(&productNode{offset: 4}) + `"}`,
+		},
+		{
+			name:       "before",
+			args:       `before: "` + cursor(&productNode{offset: 1}) + `"`,
+			wantParams: paginate.Pagination{Page: 1, Limit: 1},
+			wantInfo:   `"pageInfo":{"endCursor":"` + cursor(&productNode{offset: 0}) + `","hasNextPage":true,"hasPreviousPage":false,"startCursor":"` + cursor(&productNode{offset: 0}) + `"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data []dto.ProductListResponse
+			for i := 0; i < tt.wantParams.Limit; i++ {
+				data = append(data, dto.ProductListResponse{ID: uuid.New()})
+			}
+			productUsecase := mocks.NewProductUsecase(t)
+			productUsecase.On("GetListProduct", mock.Anything, tt.wantParams, withAudit).Return(data, int64(10), nil).Once()
+
+			resp := newTestExecutor(t, testConfig, productUsecase).Execute(context.Background(), Request{
+				Query: `{ products(limit: 2, ` + tt.args + `) { edges { cursor } pageInfo { startCursor endCursor hasNextPage hasPreviousPage } } }`,
+			})
+			if len(resp.Errors) > 0 {
+				t.Fatalf("Execute() errors = %v", resp.Errors)
+			}
+			body, _ := json.Marshal(resp.Data)
+			if !strings.Contains(string(body), tt.wantInfo) {
+				t.Errorf("Execute() data = %s, want it to contain %s", body, tt.wantInfo)
+			}
+		})
+	}
+}
+
+func Test_defaultExecutor_Execute_productBatched(t *testing.T) {
+	first, second, missing := uuid.New(), uuid.New(), uuid.New()
+	createdAt := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	items := map[string]dto.ProductBatchItem{
+		first.String():   {ID: first.String(), Found: true, Product: &dto.ProductListResponse{ID: first, Title: "Mie", Audit: &dto.Audit{CreatedAt: createdAt}}},
+		second.String():  {ID: second.String(), Found: true, Product: &dto.ProductListResponse{ID: second, Title: "Sedap"}},
+		missing.String(): {ID: missing.String()},
+	}
+
+	// The fields of a selection are resolved in no particular order, so the
+	// batch holds the ids in any order.
+	productUsecase := mocks.NewProductUsecase(t)
+	productUsecase.On("GetProductsByIds", mock.Anything, mock.MatchedBy(func(ids []string) bool {
+		if len(ids) != len(items) {
+			return false
+		}
+		for _, id := range ids {
+			if _, ok := items[id]; !ok {
+				return false
+			}
+		}
+		return true
+	}), withAudit).Return(func(ctx context.Context, ids []string, fields fieldset.Fieldset) []dto.ProductBatchItem {
+		result := make([]dto.ProductBatchItem, len(ids))
+		for i, id := range ids {
+			result[i] = items[id]
+		}
+		return result
+	}, nil).Once()
+
+	resp := newTestExecutor(t, testConfig, productUsecase).Execute(context.Background(), Request{
+		Query: `query($first: ID!, $second: ID!, $missing: ID!) {
+			a: product(id: $first) { title }
+			b: product(id: $first) { id createdAt }
+			c: product(id: $second) { title }
+			d: product(id: $missing) { id }
+			e: product(id: "invalid") { id }
+		}`,
+		Variables: map[string]interface{}{"first": first.String(), "second": second.String(), "missing": missing.String()},
+	})
+
+	body, _ := json.Marshal(resp.Data)
+	for _, want := range []string{
+		`"a":{"title":"Mie"}`,
+		`"b":{"createdAt":"2023-12-01T00:00:00Z","id":"` + first.String() + `"}`,
+		`"c":{"title":"Sedap"}`,
+		`"d":null`,
+		`"e":null`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Execute() data = %s, want it to contain %s", body, want)
+		}
+	}
+	if len(resp.Errors) != 2 || resp.Errors[0].Extensions["code"] != "NOT_FOUND" || resp.Errors[1].Extensions["code"] != "NOT_FOUND" {
+		t.Errorf("Execute() errors = %v, want 2 NOT_FOUND errors", resp.Errors)
+	}
+}
+
+func Test_defaultExecutor_Execute_errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.GraphQLConfig
+		query    string
+		mock     func(productUsecase *mocks.ProductUsecase)
+		wantErr  string
+		wantCode string
+	}{
+		{
+			name:    "syntax error",
+			cfg:     testConfig,
+			query:   `{ products {`,
+			wantErr: "Syntax Error",
+		},
+		{
+			name:    "unknown field",
+			cfg:     testConfig,
+			query:   `{ products { price } }`,
+			wantErr: `Cannot query field "price"`,
+		},
+		{
+			name:    "complexity limit",
+			cfg:     config.GraphQLConfig{MaxDepth: 8, MaxComplexity: 50},
+			query:   `{ products(limit: 30) { nodes { id title description } } }`,
+			wantErr: "query complexity 121 exceeds the maximum of 50",
+		},
+		{
+			name:    "depth limit",
+			cfg:     config.GraphQLConfig{MaxDepth: 3, MaxComplexity: 300},
+			query:   `{ products { edges { node { id } } } }`,
+			wantErr: "query depth 4 exceeds the maximum of 3",
+		},
+		{
+			name:     "not found",
+			cfg:      testConfig,
+			query:    `{ product(id: "missing") { id } }`,
+			wantErr:  "Not Found",
+			wantCode: "NOT_FOUND",
+		},
+		{
+			name:  "usecase error",
+			cfg:   testConfig,
+			query: `{ product(id: "22c8e385-0b6a-4d8e-9f5e-1f0b9a4c2d11") { id } }`,
+			mock: func(productUsecase *mocks.ProductUsecase) {
+				productUsecase.On("GetProductsByIds", mock.Anything, []string{"22c8e385-0b6a-4d8e-9f5e-1f0b9a4c2d11"}, withAudit).Return(nil, libErrors.SetError(http.StatusInternalServerError, "Internal Server Error")).Once()
+			},
+			wantErr:  "Internal Server Error",
+			wantCode: "INTERNAL_SERVER_ERROR",
+		},
+		{
+			name:     "invalid cursor",
+			cfg:      testConfig,
+			query:    `{ products(after: "product:1") { totalCount } }`,
+			wantErr:  "invalid cursor",
+			wantCode: "BAD_USER_INPUT",
+		},
+		{
+			name:     "after and before",
+			cfg:      testConfig,
+			query:    `{ products(after: "b2Zmc2V0OjE=", before: "b2Zmc2V0OjQ=") { totalCount } }`,
+			wantErr:  "after and before cannot be combined",
+			wantCode: "BAD_USER_INPUT",
+		},
+		{
+			name:     "invalid input",
+			cfg:      testConfig,
+			query:    `mutation { createProduct(input: {title: "", description: "Gurih"}) }`,
+			wantErr:  "field validator for input Title failed on the required tag",
+			wantCode: "BAD_USER_INPUT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productUsecase := mocks.NewProductUsecase(t)
+			if tt.mock != nil {
+				tt.mock(productUsecase)
+			}
+
+			resp := newTestExecutor(t, tt.cfg, productUsecase).Execute(context.Background(), Request{Query: tt.query})
+			if len(resp.Errors) != 1 {
+				t.Fatalf("Execute() errors = %v, want 1 error", resp.Errors)
+			}
+			if !strings.Contains(resp.Errors[0].Message, tt.wantErr) {
+				t.Errorf("Execute() error = %v, want %v", resp.Errors[0].Message, tt.wantErr)
+			}
+			if code := resp.Errors[0].Extensions["code"]; tt.wantCode != "" && code != tt.wantCode {
+				t.Errorf("Execute() error code = %v, want %v", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func Test_defaultExecutor_Execute_updateProduct(t *testing.T) {
+	id := uuid.New()
+	req := dto.ProductRequest{Title: "Mie", Description: "Gurih", Rating: 4}
+
+	productUsecase := mocks.NewProductUsecase(t)
+	productUsecase.On("UpdateProduct", mock.Anything, id.String(), req).Return(nil).Once()
+	productUsecase.On("GetDetailProduct", mock.Anything, id.String(), fieldset.Fieldset{}).Return(dto.DetailProductResponse{ID: id, Title: "Mie"}, nil).Once()
+
+	resp := newTestExecutor(t, testConfig, productUsecase).Execute(context.Background(), Request{
+		Query:     `mutation($id: ID!) { updateProduct(id: $id, input: {title: "Mie", description: "Gurih", rating: 4}) { id title } }`,
+		Variables: map[string]interface{}{"id": id.String()},
+	})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Execute() errors = %v", resp.Errors)
+	}
+}
@@ -0,0 +1,85 @@
+package gql
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fadilahonespot/simple-api/usecase"
+	"github.com/fadilahonespot/simple-api/usecase/dto"
+	"github.com/fadilahonespot/simple-api/utils/fieldset"
+	"github.com/google/uuid"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+type productLoader = dataloader.Loader[string, dto.DetailProductResponse]
+
+type loaderKey struct{}
+
+// newProductLoader batches and deduplicates the product lookups of a single
+// request into one GetProductsByIds call.
+func newProductLoader(productUsecase usecase.ProductUsecase) *productLoader {
+	return dataloader.NewBatchedLoader(func(ctx context.Context, ids []string) []*dataloader.Result[dto.DetailProductResponse] {
+		results := make([]*dataloader.Result[dto.DetailProductResponse], len(ids))
+		notFound := &resolverError{status: http.StatusNotFound, message: http.StatusText(http.StatusNotFound)}
+
+		// GetProductsByIds refuses the whole batch for a single invalid id,
+		// which is only a product that does not exist here.
+		var valid []string
+		for i, id := range ids {
+			if _, err := uuid.Parse(id); err != nil {
+				results[i] = &dataloader.Result[dto.DetailProductResponse]{Error: notFound}
+				continue
+			}
+			valid = append(valid, id)
+		}
+		if len(valid) == 0 {
+			return results
+		}
+
+		items, err := productUsecase.GetProductsByIds(ctx, valid, fieldset.Fieldset{Expand: []string{dto.SectionAudit}})
+		for i := range results {
+			if results[i] != nil {
+				continue
+			}
+
+			switch {
+			case err != nil:
+				results[i] = &dataloader.Result[dto.DetailProductResponse]{Error: newResolverError(err)}
+			case !items[0].Found:
+				results[i] = &dataloader.Result[dto.DetailProductResponse]{Error: notFound}
+			default:
+				results[i] = &dataloader.Result[dto.DetailProductResponse]{Data: detailProduct(*items[0].Product)}
+			}
+			if err == nil {
+				items = items[1:]
+			}
+		}
+
+		return results
+	}, dataloader.WithBatchCapacity[string, dto.DetailProductResponse](maxLimit))
+}
+
+// detailProduct is the product detail of a list item read with the audit
+// section expanded.
+func detailProduct(product dto.ProductListResponse) dto.DetailProductResponse {
+	detail := dto.DetailProductResponse{
+		ID:          product.ID,
+		Title:       product.Title,
+		Description: product.Description,
+		Rating:      product.Rating,
+		Image:       product.Image,
+	}
+	if product.Audit != nil {
+		detail.CreatedAt = product.Audit.CreatedAt
+		detail.UpdatedAt = product.Audit.UpdatedAt
+	}
+	return detail
+}
+
+func withLoader(ctx context.Context, loader *productLoader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, loader)
+}
+
+func loaderFromContext(ctx context.Context) *productLoader {
+	return ctx.Value(loaderKey{}).(*productLoader)
+}
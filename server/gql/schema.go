@@ -0,0 +1,358 @@
+package gql
+
+import (
+	"encoding/base64"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fadilahonespot/simple-api/usecase"
+	"github.com/fadilahonespot/simple-api/usecase/dto"
+	"github.com/fadilahonespot/simple-api/utils/database"
+	"github.com/fadilahonespot/simple-api/utils/fieldset"
+	"github.com/fadilahonespot/simple-api/utils/paginate"
+	"github.com/go-playground/validator"
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 30
+)
+
+// productNode is the source of the Product type, offset is its position in
+// the products connection it was listed in.
+type productNode struct {
+	product dto.DetailProductResponse
+	offset  int
+}
+
+type productConnection struct {
+	nodes      []*productNode
+	offset     int
+	limit      int
+	totalCount int64
+}
+
+type resolver struct {
+	productUsecase usecase.ProductUsecase
+	validate       *validator.Validate
+}
+
+func newSchema(productUsecase usecase.ProductUsecase) (graphql.Schema, error) {
+	r := &resolver{productUsecase: productUsecase, validate: validator.New()}
+
+	productType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: productField(func(p dto.DetailProductResponse) interface{} {
+				return p.ID.String()
+			})},
+			"title": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: productField(func(p dto.DetailProductResponse) interface{} {
+				return p.Title
+			})},
+			"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: productField(func(p dto.DetailProductResponse) interface{} {
+				return p.Description
+			})},
+			"rating": &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Resolve: productField(func(p dto.DetailProductResponse) interface{} {
+				return p.Rating
+			})},
+			"image": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: productField(func(p dto.DetailProductResponse) interface{} {
+				return p.Image
+			})},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: productField(func(p dto.DetailProductResponse) interface{} {
+				return p.CreatedAt
+			})},
+			"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: productField(func(p dto.DetailProductResponse) interface{} {
+				return p.UpdatedAt
+			})},
+		},
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"page":            &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"limit":           &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"totalPage":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"hasNextPage":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"hasPreviousPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"startCursor":     &graphql.Field{Type: graphql.String},
+			"endCursor":       &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProductEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return cursor(p.Source.(*productNode)), nil
+			}},
+			"node": &graphql.Field{Type: graphql.NewNonNull(productType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source, nil
+			}},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProductConnection",
+		Fields: graphql.Fields{
+			"edges": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))), Resolve: connectionNodes},
+			"nodes": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productType))), Resolve: connectionNodes},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*productConnection).totalCount, nil
+			}},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType), Resolve: resolvePageInfo},
+		},
+	})
+
+	filterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ProductFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":  &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"rating": &graphql.InputObjectFieldConfig{Type: graphql.Float},
+		},
+	})
+
+	inputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ProductInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"rating":      &graphql.InputObjectFieldConfig{Type: graphql.Float},
+			"image":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	idArgs := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"products": &graphql.Field{
+				Type: graphql.NewNonNull(connectionType),
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: filterType},
+					"page":   &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+					"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLimit, Description: "Capped at 30."},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String, Description: "Lists the products after this cursor instead of page."},
+					"before": &graphql.ArgumentConfig{Type: graphql.String, Description: "Lists the products before this cursor instead of page."},
+				},
+				Resolve: r.products,
+			},
+			"product": &graphql.Field{
+				Type:    productType,
+				Args:    idArgs,
+				Resolve: r.product,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createProduct": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(inputType)},
+				},
+				Resolve: r.createProduct,
+			},
+			"updateProduct": &graphql.Field{
+				Type: graphql.NewNonNull(productType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(inputType)},
+				},
+				Resolve: r.updateProduct,
+			},
+			"deleteProduct": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    idArgs,
+				Resolve: r.deleteProduct,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func (r *resolver) products(p graphql.ResolveParams) (interface{}, error) {
+	params := paginate.Pagination{
+		Page:  clamp(p.Args["page"].(int), 1, math.MaxInt32),
+		Limit: clamp(p.Args["limit"].(int), 1, maxLimit),
+	}
+	if filter, ok := p.Args["filter"].(map[string]interface{}); ok {
+		params.Title, _ = filter["title"].(string)
+		params.Rating, _ = filter["rating"].(float64)
+	}
+
+	connection := &productConnection{nodes: []*productNode{}, offset: (params.Page - 1) * params.Limit, limit: params.Limit}
+	after, hasAfter := p.Args["after"].(string)
+	before, hasBefore := p.Args["before"].(string)
+	switch {
+	case hasAfter && hasBefore:
+		return nil, &resolverError{status: http.StatusBadRequest, message: "after and before cannot be combined"}
+	case hasAfter:
+		offset, err := parseCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		connection.offset = offset + 1
+	case hasBefore:
+		offset, err := parseCursor(before)
+		if err != nil {
+			return nil, err
+		}
+		connection.offset = offset - params.Limit
+		if connection.offset < 0 {
+			connection.offset = 0
+		}
+		params.Limit = offset - connection.offset
+	}
+	if hasAfter || hasBefore {
+		params.Page, params.Offset = 1, connection.offset
+	}
+
+	// The audit section carries the timestamps, so selecting them needs no
+	// lookup per product.
+	data, count, err := r.productUsecase.GetListProduct(p.Context, params, fieldset.Fieldset{Expand: []string{dto.SectionAudit}})
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+
+	connection.totalCount = count
+	for i, product := range data {
+		connection.nodes = append(connection.nodes, &productNode{product: detailProduct(product), offset: connection.offset + i})
+	}
+	return connection, nil
+}
+
+func (r *resolver) product(p graphql.ResolveParams) (interface{}, error) {
+	thunk := loaderFromContext(p.Context).Load(p.Context, p.Args["id"].(string))
+	return func() (interface{}, error) {
+		data, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		return &productNode{product: data}, nil
+	}, nil
+}
+
+func (r *resolver) createProduct(p graphql.ResolveParams) (interface{}, error) {
+	req, err := r.productRequest(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.productUsecase.CreateProduct(p.Context, req)
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+	return true, nil
+}
+
+func (r *resolver) updateProduct(p graphql.ResolveParams) (interface{}, error) {
+	req, err := r.productRequest(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+
+	id := p.Args["id"].(string)
+	err = r.productUsecase.UpdateProduct(p.Context, id, req)
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+
+	data, err := r.productUsecase.GetDetailProduct(database.WithPrimary(p.Context), id, fieldset.Fieldset{})
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+	loaderFromContext(p.Context).Clear(p.Context, id)
+	return &productNode{product: data}, nil
+}
+
+func (r *resolver) deleteProduct(p graphql.ResolveParams) (interface{}, error) {
+	id := p.Args["id"].(string)
+	err := r.productUsecase.DeleteProduct(p.Context, id)
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+	loaderFromContext(p.Context).Clear(p.Context, id)
+	return true, nil
+}
+
+func (r *resolver) productRequest(input interface{}) (req dto.ProductRequest, err error) {
+	fields, _ := input.(map[string]interface{})
+	req.Title, _ = fields["title"].(string)
+	req.Description, _ = fields["description"].(string)
+	req.Rating, _ = fields["rating"].(float64)
+	req.Image, _ = fields["image"].(string)
+
+	err = r.validate.Struct(req)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok && len(validationErrors) > 0 {
+			return req, &resolverError{status: http.StatusBadRequest, message: "field validator for input " + validationErrors[0].Field() + " failed on the " + validationErrors[0].ActualTag() + " tag"}
+		}
+		return req, &resolverError{status: http.StatusBadRequest, message: err.Error()}
+	}
+	return
+}
+
+func productField(field func(dto.DetailProductResponse) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return field(p.Source.(*productNode).product), nil
+	}
+}
+
+func connectionNodes(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(*productConnection).nodes, nil
+}
+
+func resolvePageInfo(p graphql.ResolveParams) (interface{}, error) {
+	connection := p.Source.(*productConnection)
+	totalPage := int(math.Ceil(float64(connection.totalCount) / float64(connection.limit)))
+
+	pageInfo := map[string]interface{}{
+		"page":            connection.offset/connection.limit + 1,
+		"limit":           connection.limit,
+		"totalPage":       totalPage,
+		"hasNextPage":     int64(connection.offset+len(connection.nodes)) < connection.totalCount,
+		"hasPreviousPage": connection.offset > 0,
+	}
+	if len(connection.nodes) > 0 {
+		pageInfo["startCursor"] = cursor(connection.nodes[0])
+		pageInfo["endCursor"] = cursor(connection.nodes[len(connection.nodes)-1])
+	}
+	return pageInfo, nil
+}
+
+// cursor encodes the offset of node, the products connection is paged by
+// offset like the REST list.
+func cursor(node *productNode) string {
+	return base64.StdEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(node.offset)))
+}
+
+func parseCursor(value string) (offset int, err error) {
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err == nil {
+		offset, err = strconv.Atoi(strings.TrimPrefix(string(decoded), "offset:"))
+	}
+	if err != nil || offset < 0 || offset > math.MaxInt32 || !strings.HasPrefix(string(decoded), "offset:") {
+		return 0, &resolverError{status: http.StatusBadRequest, message: "invalid cursor " + value}
+	}
+	return
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
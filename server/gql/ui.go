@@ -0,0 +1,17 @@
+package gql
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed graphiql.html
+var graphiqlPage []byte
+
+func GraphiQLHandler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.HTMLBlob(http.StatusOK, graphiqlPage)
+	}
+}
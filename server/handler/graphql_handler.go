@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/simple-api/server/gql"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type GraphQLHandler struct {
+	executor gql.Executor
+	log      logger.Logger
+}
+
+func NewGraphQLHandler(executor gql.Executor, log logger.Logger) GraphQLHandler {
+	return GraphQLHandler{executor: executor, log: log}
+}
+
+func (h *GraphQLHandler) Query(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	var req gql.Request
+	err = c.Bind(&req)
+	if err != nil {
+		h.log.Error(ctx, "error binding", err.Error())
+		err = errors.SetError(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		return
+	}
+
+	err = c.Validate(req)
+	if err != nil {
+		h.log.Error(ctx, "error validating", err.Error())
+		err = errors.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := h.executor.Execute(ctx, req)
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/simple-api/utils/health"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type HealthHandler struct {
+	registry health.Registry
+	log      logger.Logger
+}
+
+func NewHealthHandler(registry health.Registry, log logger.Logger) HealthHandler {
+	return HealthHandler{registry: registry, log: log}
+}
+
+func (h *HealthHandler) Liveness(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	report := h.registry.Liveness(ctx)
+	if !report.IsUp() {
+		h.log.Warn(ctx, "Liveness check failed", report)
+	}
+	return c.JSON(healthStatusCode(report), report)
+}
+
+func (h *HealthHandler) Readiness(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	report := h.registry.Readiness(ctx)
+	if !report.IsUp() {
+		h.log.Warn(ctx, "Readiness check failed", report)
+	}
+	return c.JSON(healthStatusCode(report), report)
+}
+
+func healthStatusCode(report health.Report) int {
+	if report.IsUp() {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/library/response"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type LogLevelHandler struct {
+	levels *logger.Levels
+	log    logger.Logger
+}
+
+func NewLogLevelHandler(levels *logger.Levels, log logger.Logger) LogLevelHandler {
+	return LogLevelHandler{levels: levels, log: log}
+}
+
+func (h *LogLevelHandler) GetLevels(c echo.Context) (err error) {
+	resp := response.ResponseSuccess(h.levels.Report())
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *LogLevelHandler) SetLevel(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	var req logger.LevelChange
+	err = c.Bind(&req)
+	if err != nil {
+		return errors.SetError(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+	}
+
+	err = h.levels.Apply(req)
+	if err != nil {
+		return errors.SetError(http.StatusBadRequest, err.Error())
+	}
+	h.log.Warn(ctx, "Log level changed", req)
+
+	resp := response.ResponseSuccess(h.levels.Report())
+	return c.JSON(http.StatusOK, resp)
+}
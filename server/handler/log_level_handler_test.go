@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	mockUtils "github.com/fadilahonespot/simple-api/utils/mocks"
+)
+
+func TestLogLevelHandler_SetLevel(t *testing.T) {
+	tests := []struct {
+		name        string
+		bodyRequest interface{}
+		wantErr     bool
+		wantLevel   logger.Level
+	}{
+		{
+			name:        "error binding data",
+			bodyRequest: []string{"debug"},
+			wantErr:     true,
+			wantLevel:   logger.LevelInfo,
+		},
+		{
+			name:        "unknown level",
+			bodyRequest: logger.LevelChange{Package: "usecase", Level: "trace"},
+			wantErr:     true,
+			wantLevel:   logger.LevelInfo,
+		},
+		{
+			name:        "set package level",
+			bodyRequest: logger.LevelChange{Package: "usecase", Level: "debug"},
+			wantLevel:   logger.LevelDebug,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := logger.NewLevels(logger.LevelInfo)
+
+			ctx, rec := mockUtils.MockEcho(http.MethodPut, "/admin/log-levels", nil, tt.bodyRequest)
+			svc := NewLogLevelHandler(levels, logger.New(logger.NewRecorder(), nil))
+			if err := svc.SetLevel(ctx); (err != nil) != tt.wantErr {
+				t.Fatalf("LogLevelHandler.SetLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && rec.Code != http.StatusOK {
+				t.Errorf("LogLevelHandler.SetLevel() status = %v, want %v", rec.Code, http.StatusOK)
+			}
+			if got := levels.Level("usecase"); got != tt.wantLevel {
+				t.Errorf("level of usecase = %v, want %v", got, tt.wantLevel)
+			}
+		})
+	}
+}
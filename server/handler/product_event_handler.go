@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/simple-api/utils/events"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	MIMETextEventStream = "text/event-stream"
+	headerLastEventID   = "Last-Event-ID"
+	eventReset          = "reset"
+	reconnectDelay      = 3 * time.Second
+)
+
+var productEventTypes = map[string]string{
+	"created": events.ProductCreated,
+	"updated": events.ProductUpdated,
+	"deleted": events.ProductDeleted,
+}
+
+type ProductEventHandler struct {
+	broker    events.Broker
+	heartbeat time.Duration
+	log       logger.Logger
+}
+
+func NewProductEventHandler(broker events.Broker, heartbeat time.Duration, log logger.Logger) ProductEventHandler {
+	return ProductEventHandler{broker: broker, heartbeat: heartbeat, log: log}
+}
+
+func (h *ProductEventHandler) StreamEvents(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	filter, err := productEventFilter(c.QueryParam("types"), c.QueryParam("productId"))
+	if err != nil {
+		h.log.Error(ctx, "error parsing event filter", err.Error())
+		return errors.SetError(http.StatusBadRequest, err.Error())
+	}
+
+	lastEventID := c.Request().Header.Get(headerLastEventID)
+	if lastEventID == "" {
+		lastEventID = c.QueryParam("lastEventId")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		lastID, err = strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			h.log.Error(ctx, "error parsing last event id", err.Error())
+			return errors.SetError(http.StatusBadRequest, "Last-Event-ID must be a positive integer")
+		}
+	}
+
+	sub, replay, complete := h.broker.Subscribe(lastID, filter)
+	defer sub.Close()
+
+	res := c.Response()
+	// The stream outlives the read and write timeouts of the server.
+	controller := http.NewResponseController(res)
+	controller.SetReadDeadline(time.Time{})
+	controller.SetWriteDeadline(time.Time{})
+
+	res.Header().Set(echo.HeaderContentType, MIMETextEventStream)
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(res, "retry: %d\n\n", reconnectDelay.Milliseconds())
+	if !complete {
+		// Some events since Last-Event-ID are gone, the client has to refetch.
+		fmt.Fprintf(res, "event: %s\ndata: {}\n\n", eventReset)
+	}
+	for _, event := range replay {
+		err = writeEvent(res, event)
+		if err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.Events():
+			if !ok {
+				if sub.Err() != nil {
+					h.log.Info(ctx, "Closing event stream", sub.Err().Error())
+				}
+				return nil
+			}
+			err = writeEvent(res, event)
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(res, ": heartbeat\n\n")
+		}
+		if err != nil {
+			return nil
+		}
+		res.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+func productEventFilter(types, productId string) (events.Filter, error) {
+	allowed := make(map[string]bool)
+	for _, name := range strings.Split(types, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		eventType, ok := productEventTypes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown event type %q", name)
+		}
+		allowed[eventType] = true
+	}
+
+	if len(allowed) == 0 && productId == "" {
+		return nil, nil
+	}
+
+	return func(event events.Event) bool {
+		if len(allowed) > 0 && !allowed[event.Type] {
+			return false
+		}
+		return productId == "" || event.Key == productId
+	}, nil
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/events"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+func TestProductEventHandler_StreamEvents(t *testing.T) {
+	logger.NewLogger(config.LoggerConfig{})
+
+	tests := []struct {
+		name        string
+		target      string
+		lastEventID string
+		wantErr     bool
+		want        []string
+		notWant     []string
+	}{
+		{
+			name:    "unknown event type",
+			target:  "/products/events?types=archived",
+			wantErr: true,
+		},
+		{
+			name:        "invalid last event id",
+			target:      "/products/events",
+			lastEventID: "abc",
+			wantErr:     true,
+		},
+		{
+			name:    "no replay without last event id",
+			target:  "/products/events",
+			want:    []string{"retry: 3000"},
+			notWant: []string{"id: "},
+		},
+		{
+			name:        "replay after last event id",
+			target:      "/products/events",
+			lastEventID: "2",
+			want:        []string{"id: 3\nevent: product.updated", "id: 4\nevent: product.deleted"},
+			notWant:     []string{"id: 2\n", "event: reset"},
+		},
+		{
+			name:    "replay from query param with filter",
+			target:  "/products/events?lastEventId=2&types=deleted&productId=p1",
+			want:    []string{"id: 4\nevent: product.deleted"},
+			notWant: []string{"product.updated", "event: reset"},
+		},
+		{
+			name:        "reset when events were evicted",
+			target:      "/products/events",
+			lastEventID: "1",
+			want:        []string{"event: reset", "id: 3\n", "id: 4\n"},
+		},
+		{
+			name:        "reset when last event id is unknown",
+			target:      "/products/events",
+			lastEventID: "10",
+			want:        []string{"event: reset"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := events.NewBroker(2, 8)
+			broker.Publish(events.ProductCreated, "p1", nil)
+			broker.Publish(events.ProductCreated, "p2", nil)
+			broker.Publish(events.ProductUpdated, "p1", nil)
+			broker.Publish(events.ProductDeleted, "p1", nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil).WithContext(ctx)
+			if tt.lastEventID != "" {
+				req.Header.Set(headerLastEventID, tt.lastEventID)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			h := NewProductEventHandler(broker, time.Hour, logger.New(logger.NewRecorder(), nil))
+			err := h.StreamEvents(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProductEventHandler.StreamEvents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := rec.Header().Get(echo.HeaderContentType); got != MIMETextEventStream {
+				t.Errorf("Content-Type = %q, want %q", got, MIMETextEventStream)
+			}
+			body := rec.Body.String()
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("body %q does not contain %q", body, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(body, notWant) {
+					t.Errorf("body %q contains %q", body, notWant)
+				}
+			}
+		})
+	}
+}
@@ -2,11 +2,14 @@ package handler
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/fadilahonespot/library/errors"
 	"github.com/fadilahonespot/library/response"
+	"github.com/fadilahonespot/simple-api/server/render"
 	"github.com/fadilahonespot/simple-api/usecase"
 	"github.com/fadilahonespot/simple-api/usecase/dto"
+	"github.com/fadilahonespot/simple-api/utils/fieldset"
 	"github.com/fadilahonespot/simple-api/utils/logger"
 	"github.com/fadilahonespot/simple-api/utils/paginate"
 	"github.com/labstack/echo/v4"
@@ -14,31 +17,32 @@ import (
 
 type ProductHandler struct {
 	productUsecase usecase.ProductUsecase
+	log            logger.Logger
 }
 
-func NewProductHandler(productUsecase usecase.ProductUsecase) ProductHandler {
-	return ProductHandler{productUsecase: productUsecase}
+func NewProductHandler(productUsecase usecase.ProductUsecase, log logger.Logger) ProductHandler {
+	return ProductHandler{productUsecase: productUsecase, log: log}
 }
 
 func (h *ProductHandler) AddProduct(c echo.Context) (err error) {
 	ctx := c.Request().Context()
 
 	var req dto.ProductRequest
-	err = c.Bind(&req)
+	err = render.Bind(c, &req)
 	if err != nil {
-		logger.Error(ctx, "error binding", err.Error())
+		h.log.Error(ctx, "error binding", err.Error())
 		err = errors.SetError(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
 		return
 	}
 
 	err = c.Validate(req)
 	if err != nil {
-		logger.Error(ctx, "error validating", err.Error())
+		h.log.Error(ctx, "error validating", err.Error())
 		err = errors.SetError(http.StatusBadRequest, err.Error())
 		return
 	}
 
-	logger.Info(ctx, "[Request]", req)
+	h.log.Info(ctx, "[Request]", req)
 
 	err = h.productUsecase.CreateProduct(ctx, req)
 	if err != nil {
@@ -46,32 +50,103 @@ func (h *ProductHandler) AddProduct(c echo.Context) (err error) {
 	}
 
 	resp := response.ResponseSuccess(nil)
-	return c.JSON(http.StatusOK, resp)
+	return render.Render(c, http.StatusOK, resp)
 }
 
 func (h *ProductHandler) GetListProduct(c echo.Context) (err error) {
 	ctx := c.Request().Context()
+	if ids := c.QueryParam("ids"); ids != "" {
+		return h.getProductsByIds(c, strings.Split(ids, ","))
+	}
+
 	params := paginate.GetParams(c)
 	
-	data, count, err := h.productUsecase.GetListProduct(ctx, params)
+	fields, err := fieldset.Parse(c, dto.ProductListResponse{}, dto.SectionAudit)
+	if err != nil {
+		h.log.Error(ctx, "error parsing fields", err.Error())
+		return
+	}
+
+	data, count, err := h.productUsecase.GetListProduct(ctx, params, fields)
 	if err != nil {
 		return err
 	}
 
-	resp := response.HandleSuccessWithPagination(float64(count), params.Limit, params.Page, data)
-	return c.JSON(http.StatusOK, resp)
+	body, err := fields.Apply(data)
+	if err != nil {
+		return
+	}
+
+	resp := response.HandleSuccessWithPagination(float64(count), params.Limit, params.Page, body)
+	return render.Render(c, http.StatusOK, resp)
+}
+
+func (h *ProductHandler) BatchGetProducts(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	var req dto.ProductBatchRequest
+	err = render.Bind(c, &req)
+	if err != nil {
+		h.log.Error(ctx, "error binding", err.Error())
+		err = errors.SetError(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		return
+	}
+
+	err = c.Validate(req)
+	if err != nil {
+		h.log.Error(ctx, "error validating", err.Error())
+		err = errors.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	return h.getProductsByIds(c, req.IDs)
+}
+
+// getProductsByIds renders the products of ids in their order, for both
+// GET /products?ids= and POST /products/batch-get.
+func (h *ProductHandler) getProductsByIds(c echo.Context, ids []string) (err error) {
+	ctx := c.Request().Context()
+	fields, err := fieldset.Parse(c, dto.ProductListResponse{}, dto.SectionAudit)
+	if err != nil {
+		h.log.Error(ctx, "error parsing fields", err.Error())
+		return
+	}
+
+	data, err := h.productUsecase.GetProductsByIds(ctx, ids, fields)
+	if err != nil {
+		return
+	}
+
+	body, err := fields.ApplyTo(data, "product")
+	if err != nil {
+		return
+	}
+
+	resp := response.ResponseSuccess(body)
+	return render.Render(c, http.StatusOK, resp)
 }
 
 func (h *ProductHandler) GetProductDetail(c echo.Context) (err error) {
 	ctx := c.Request().Context()
 	productId := c.Param("productId")
-	data, err := h.productUsecase.GetDetailProduct(ctx, productId)
+	fields, err := fieldset.Parse(c, dto.DetailProductResponse{}, dto.SectionAudit)
+	if err != nil {
+		h.log.Error(ctx, "error parsing fields", err.Error())
+		return
+	}
+
+	data, err := h.productUsecase.GetDetailProduct(ctx, productId, fields)
+	if err != nil {
+		return
+	}
+
+	body, err := fields.Apply(data)
 	if err != nil {
 		return
 	}
 
-	resp := response.ResponseSuccess(data)
-	return c.JSON(http.StatusOK, resp)
+	resp := response.ResponseSuccess(body)
+	return render.Render(c, http.StatusOK, resp)
 }
 
 func (h *ProductHandler) UpdateProduct(c echo.Context) (err error) {
@@ -79,21 +154,21 @@ func (h *ProductHandler) UpdateProduct(c echo.Context) (err error) {
 	productId := c.Param("productId")
 
 	var req dto.ProductRequest
-	err = c.Bind(&req)
+	err = render.Bind(c, &req)
 	if err != nil {
-		logger.Error(ctx, "error binding", err.Error())
+		h.log.Error(ctx, "error binding", err.Error())
 		err = errors.SetError(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
 		return
 	}
 
 	err = c.Validate(req)
 	if err != nil {
-		logger.Error(ctx, "error validating", err.Error())
+		h.log.Error(ctx, "error validating", err.Error())
 		err = errors.SetError(http.StatusBadRequest, err.Error())
 		return
 	}
 
-	logger.Info(ctx, "[Request]", req)
+	h.log.Info(ctx, "[Request]", req)
 
 	err = h.productUsecase.UpdateProduct(ctx, productId, req)
 	if err != nil {
@@ -101,7 +176,7 @@ func (h *ProductHandler) UpdateProduct(c echo.Context) (err error) {
 	}
 
 	resp := response.ResponseSuccess(nil)
-	return c.JSON(http.StatusOK, resp)
+	return render.Render(c, http.StatusOK, resp)
 }
 
 func (h *ProductHandler) DeleteProduct(c echo.Context) (err error) {
@@ -113,5 +188,5 @@ func (h *ProductHandler) DeleteProduct(c echo.Context) (err error) {
 	}
 
 	resp := response.ResponseSuccess(nil)
-	return c.JSON(http.StatusOK, resp)
+	return render.Render(c, http.StatusOK, resp)
 }
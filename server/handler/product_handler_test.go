@@ -3,6 +3,7 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/fadilahonespot/simple-api/usecase/dto"
@@ -14,18 +15,18 @@ import (
 )
 
 func TestProductHandler_AddProduct(t *testing.T) {
-	logger.NewLogger()
-
 	tests := []struct {
 		name             string
 		createProductErr error
 		bodyRequest      interface{}
 		wantErr          bool
+		wantLog          string
 	}{
 		{
 			name:        "error binding data",
 			bodyRequest: map[string]string{"rating": "1"},
 			wantErr:     true,
+			wantLog:     "error binding",
 		},
 		{
 			name: "error validate data: title is empty",
@@ -34,6 +35,7 @@ func TestProductHandler_AddProduct(t *testing.T) {
 				Description: "Taburan ayam gurih nikmat di setiap kemasan",
 			},
 			wantErr: true,
+			wantLog: "error validating",
 		},
 		{
 			name: "create product failed",
@@ -59,31 +61,59 @@ func TestProductHandler_AddProduct(t *testing.T) {
 			productUsecase.On("CreateProduct", mock.Anything, mock.Anything).Return(tt.createProductErr).Once()
 
 			ctx, _ := mockUtils.MockEcho(http.MethodPost, "/products", nil, tt.bodyRequest)
-			svc := NewProductHandler(productUsecase)
+			recorder := logger.NewRecorder()
+			svc := NewProductHandler(productUsecase, logger.New(recorder, nil))
 			if err := svc.AddProduct(ctx); (err != nil) != tt.wantErr {
 				t.Errorf("ProductHandler.AddProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if tt.wantLog != "" && !recorder.Has(logger.LevelError, tt.wantLog) {
+				t.Errorf("ProductHandler.AddProduct() logs = %+v, want error %q", recorder.Entries(), tt.wantLog)
+			}
 		})
 	}
 }
 
 func TestProductHandler_GetListProduct(t *testing.T) {
-	logger.NewLogger()
 	uidStr := "a1b91cb9-c4a5-408f-ad28-5f32e197d954"
 	uid, _ := uuid.Parse(uidStr)
 
 	tests := []struct {
 		name      string
+		path      string
 		listResp  []dto.ProductListResponse
 		listCount int64
 		listErr   error
 		wantErr   bool
+		wantData  string
 	}{
 		{
 			name:    "error get list product",
 			listErr: errors.New("error get list product"),
 			wantErr: true,
 		},
+		{
+			name:    "unknown field",
+			path:    "/products?fields=id,price",
+			wantErr: true,
+		},
+		{
+			name:    "unknown section to expand",
+			path:    "/products?expand=reviews",
+			wantErr: true,
+		},
+		{
+			name: "sparse fieldset",
+			path: "/products?fields=image,id",
+			listResp: []dto.ProductListResponse{
+				{
+					ID:    uid,
+					Title: "Mie indomi Rasa ayam Bawang",
+					Image: "http://google.com/image.jpg",
+				},
+			},
+			listCount: 1,
+			wantData:  `"data":[{"id":"` + uidStr + `","image":"http://google.com/image.jpg"}]`,
+		},
 		{
 			name: "succes get list product",
 			listResp: []dto.ProductListResponse{
@@ -102,20 +132,124 @@ func TestProductHandler_GetListProduct(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			productUsecase := new(mocks.ProductUsecase)
-			productUsecase.On("GetListProduct", mock.Anything, mock.Anything).Return(tt.listResp, tt.listCount, tt.listErr).Once()
+			productUsecase.On("GetListProduct", mock.Anything, mock.Anything, mock.Anything).Return(tt.listResp, tt.listCount, tt.listErr).Once()
 
-			ctx, _ := mockUtils.MockEcho(http.MethodGet, "/products", nil, nil)
-			svc := NewProductHandler(productUsecase)
+			path := tt.path
+			if path == "" {
+				path = "/products"
+			}
+			ctx, rec := mockUtils.MockEcho(http.MethodGet, path, nil, nil)
+			svc := NewProductHandler(productUsecase, logger.New(logger.NewRecorder(), nil))
 
 			if err := svc.GetListProduct(ctx); (err != nil) != tt.wantErr {
 				t.Errorf("ProductHandler.GetListProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if tt.wantData != "" && !strings.Contains(rec.Body.String(), tt.wantData) {
+				t.Errorf("ProductHandler.GetListProduct() body = %s, want %s", rec.Body.String(), tt.wantData)
+			}
+		})
+	}
+}
+
+func TestProductHandler_BatchGetProducts(t *testing.T) {
+	uidStr := "a1b91cb9-c4a5-408f-ad28-5f32e197d954"
+	uid, _ := uuid.Parse(uidStr)
+	missing := "22c8e385-6d60-4ddb-87b2-3fb543d43177"
+	items := []dto.ProductBatchItem{
+		{ID: uidStr, Found: true, Product: &dto.ProductListResponse{ID: uid, Title: "Mie indomi Rasa ayam Bawang", Rating: 8.1}},
+		{ID: missing},
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		bodyRequest interface{}
+		wantIds     []string
+		batchErr    error
+		wantErr     bool
+		wantLog     string
+		wantData    string
+	}{
+		{
+			name:        "error binding data",
+			method:      http.MethodPost,
+			path:        "/products/batch-get",
+			bodyRequest: map[string]string{"ids": uidStr},
+			wantErr:     true,
+			wantLog:     "error binding",
+		},
+		{
+			name:        "error validate data: ids is empty",
+			method:      http.MethodPost,
+			path:        "/products/batch-get",
+			bodyRequest: dto.ProductBatchRequest{},
+			wantErr:     true,
+			wantLog:     "error validating",
+		},
+		{
+			name:        "batch get failed",
+			method:      http.MethodPost,
+			path:        "/products/batch-get",
+			bodyRequest: dto.ProductBatchRequest{IDs: []string{uidStr, "1"}},
+			wantIds:     []string{uidStr, "1"},
+			batchErr:    errors.New("invalid product ids"),
+			wantErr:     true,
+		},
+		{
+			name:        "batch get success",
+			method:      http.MethodPost,
+			path:        "/products/batch-get",
+			bodyRequest: dto.ProductBatchRequest{IDs: []string{uidStr, missing}},
+			wantIds:     []string{uidStr, missing},
+			wantData:    `{"id":"` + missing + `","found":false,"product":null}`,
+		},
+		{
+			name:        "batch get sparse fieldset",
+			method:      http.MethodPost,
+			path:        "/products/batch-get?fields=title",
+			bodyRequest: dto.ProductBatchRequest{IDs: []string{uidStr, missing}},
+			wantIds:     []string{uidStr, missing},
+			wantData:    `"data":[{"id":"` + uidStr + `","found":true,"product":{"title":"Mie indomi Rasa ayam Bawang"}},`,
+		},
+		{
+			name:     "ids of the product list",
+			method:   http.MethodGet,
+			path:     "/products?ids=" + uidStr + "," + missing + "&fields=title",
+			wantIds:  []string{uidStr, missing},
+			wantData: `"data":[{"id":"` + uidStr + `","found":true,"product":{"title":"Mie indomi Rasa ayam Bawang"}},`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productUsecase := new(mocks.ProductUsecase)
+			if tt.wantIds != nil {
+				productUsecase.On("GetProductsByIds", mock.Anything, tt.wantIds, mock.Anything).Return(items, tt.batchErr).Once()
+			}
+
+			ctx, rec := mockUtils.MockEcho(tt.method, tt.path, nil, tt.bodyRequest)
+			recorder := logger.NewRecorder()
+			svc := NewProductHandler(productUsecase, logger.New(recorder, nil))
+
+			handle := svc.BatchGetProducts
+			if tt.method == http.MethodGet {
+				handle = svc.GetListProduct
+			}
+			if err := handle(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("ProductHandler.BatchGetProducts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantLog != "" && !recorder.Has(logger.LevelError, tt.wantLog) {
+				t.Errorf("ProductHandler.BatchGetProducts() logs = %+v, want error %q", recorder.Entries(), tt.wantLog)
+			}
+			if tt.wantData != "" && !strings.Contains(rec.Body.String(), tt.wantData) {
+				t.Errorf("ProductHandler.BatchGetProducts() body = %s, want %s", rec.Body.String(), tt.wantData)
+			}
+			productUsecase.AssertExpectations(t)
 		})
 	}
 }
 
 func TestProductHandler_GetProductDetail(t *testing.T) {
-	logger.NewLogger()
 	uidStr := "a1b91cb9-c4a5-408f-ad28-5f32e197d954"
 	uid, _ := uuid.Parse(uidStr)
 
@@ -145,10 +279,10 @@ func TestProductHandler_GetProductDetail(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			productUsecase := new(mocks.ProductUsecase)
-			productUsecase.On("GetDetailProduct", mock.Anything, mock.Anything).Return(tt.productDetailResp, tt.productDetailErr).Once()
+			productUsecase.On("GetDetailProduct", mock.Anything, mock.Anything, mock.Anything).Return(tt.productDetailResp, tt.productDetailErr).Once()
 
 			ctx, _ := mockUtils.MockEcho(http.MethodGet, "/products", nil, nil)
-			svc := NewProductHandler(productUsecase)
+			svc := NewProductHandler(productUsecase, logger.New(logger.NewRecorder(), nil))
 
 			if err := svc.GetProductDetail(ctx); (err != nil) != tt.wantErr {
 				t.Errorf("ProductHandler.GetProductDetail() error = %v, wantErr %v", err, tt.wantErr)
@@ -158,7 +292,6 @@ func TestProductHandler_GetProductDetail(t *testing.T) {
 }
 
 func TestProductHandler_UpdateProduct(t *testing.T) {
-	logger.NewLogger()
 	uidStr := "a1b91cb9-c4a5-408f-ad28-5f32e197d954"
 
 	tests := []struct {
@@ -204,7 +337,7 @@ func TestProductHandler_UpdateProduct(t *testing.T) {
 			productUsecase.On("UpdateProduct", mock.Anything, mock.Anything, mock.Anything).Return(tt.updateErr).Once()
 
 			ctx, _ := mockUtils.MockEcho(http.MethodPut, "/products/"+uidStr, nil, tt.bodyRequest)
-			svc := NewProductHandler(productUsecase)
+			svc := NewProductHandler(productUsecase, logger.New(logger.NewRecorder(), nil))
 
 			if err := svc.UpdateProduct(ctx); (err != nil) != tt.wantErr {
 				t.Errorf("ProductHandler.UpdateProduct() error = %v, wantErr %v", err, tt.wantErr)
@@ -214,7 +347,6 @@ func TestProductHandler_UpdateProduct(t *testing.T) {
 }
 
 func TestProductHandler_DeleteProduct(t *testing.T) {
-	logger.NewLogger()
 	uidStr := "a1b91cb9-c4a5-408f-ad28-5f32e197d954"
 	tests := []struct {
 		name      string
@@ -237,7 +369,7 @@ func TestProductHandler_DeleteProduct(t *testing.T) {
 			productUsecase.On("DeleteProduct", mock.Anything, mock.Anything).Return(tt.deleteErr).Once()
 
 			ctx, _ := mockUtils.MockEcho(http.MethodDelete, "/products/"+uidStr, nil, nil)
-			svc := NewProductHandler(productUsecase)
+			svc := NewProductHandler(productUsecase, logger.New(logger.NewRecorder(), nil))
 
 			if err := svc.DeleteProduct(ctx); (err != nil) != tt.wantErr {
 				t.Errorf("ProductHandler.DeleteProduct() error = %v, wantErr %v", err, tt.wantErr)
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/library/response"
+	"github.com/fadilahonespot/simple-api/server/render"
+	"github.com/fadilahonespot/simple-api/usecase"
+	"github.com/fadilahonespot/simple-api/usecase/dto"
+	"github.com/fadilahonespot/simple-api/utils/fieldset"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// ProductV2Handler serves the v2 product API. Endpoints that did not change
+// since v1 are served by the embedded ProductHandler.
+type ProductV2Handler struct {
+	ProductHandler
+}
+
+func NewProductV2Handler(productUsecase usecase.ProductUsecase, log logger.Logger) ProductV2Handler {
+	return ProductV2Handler{ProductHandler: NewProductHandler(productUsecase, log)}
+}
+
+func (h *ProductV2Handler) GetProductDetail(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+	productId := c.Param("productId")
+	fields, err := fieldset.Parse(c, dto.ProductResponseV2{}, dto.SectionAudit)
+	if err != nil {
+		h.log.Error(ctx, "error parsing fields", err.Error())
+		return
+	}
+
+	data, err := h.productUsecase.GetDetailProduct(ctx, productId, fields)
+	if err != nil {
+		return
+	}
+
+	body, err := fields.Apply(dto.ProductResponseV2{
+		ID:          data.ID,
+		Title:       data.Title,
+		Description: data.Description,
+		Rating:      data.Rating,
+		Image:       data.Image,
+		CreatedAt:   data.CreatedAt,
+		UpdatedAt:   data.UpdatedAt,
+		Audit:       data.Audit,
+	})
+	if err != nil {
+		return
+	}
+
+	resp := response.ResponseSuccess(body)
+	return render.Render(c, http.StatusOK, resp)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fadilahonespot/simple-api/usecase/dto"
+	"github.com/fadilahonespot/simple-api/usecase/mocks"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	mockUtils "github.com/fadilahonespot/simple-api/utils/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProductV2Handler_GetProductDetail(t *testing.T) {
+	uid, _ := uuid.Parse("a1b91cb9-c4a5-408f-ad28-5f32e197d954")
+
+	tests := []struct {
+		name              string
+		productDetailResp dto.DetailProductResponse
+		productDetailErr  error
+		wantErr           bool
+	}{
+		{
+			name:             "error get product detail",
+			productDetailErr: errors.New("error get product detail"),
+			wantErr:          true,
+		},
+		{
+			name: "success get product detail",
+			productDetailResp: dto.DetailProductResponse{
+				ID:          uid,
+				Title:       "Mie indomi Rasa ayam Bawang",
+				Description: "Taburan ayam gurih nikmat di setiap kemasan",
+				Rating:      8.1,
+				Image:       "http://google.com/image.jpg",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productUsecase := new(mocks.ProductUsecase)
+			productUsecase.On("GetDetailProduct", mock.Anything, mock.Anything, mock.Anything).Return(tt.productDetailResp, tt.productDetailErr).Once()
+
+			ctx, rec := mockUtils.MockEcho(http.MethodGet, "/v2/products", nil, nil)
+			svc := NewProductV2Handler(productUsecase, logger.New(logger.NewRecorder(), nil))
+
+			if err := svc.GetProductDetail(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("ProductV2Handler.GetProductDetail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && strings.Contains(rec.Body.String(), "deletedAt") {
+				t.Errorf("ProductV2Handler.GetProductDetail() body = %s, want no deletedAt", rec.Body.String())
+			}
+		})
+	}
+}
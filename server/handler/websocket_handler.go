@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/simple-api/server/ws"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type WebSocketHandler struct {
+	hub ws.Hub
+	log logger.Logger
+}
+
+func NewWebSocketHandler(hub ws.Hub, log logger.Logger) WebSocketHandler {
+	return WebSocketHandler{hub: hub, log: log}
+}
+
+func (h *WebSocketHandler) Connect(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	err = h.hub.Connect(c.Response(), c.Request())
+	switch err {
+	case nil:
+		return
+	case ws.ErrUnauthorized:
+		h.log.Error(ctx, "error authenticating WebSocket", err.Error())
+		return errors.SetError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+	case ws.ErrTooManyConnections:
+		h.log.Error(ctx, "error accepting WebSocket", err.Error())
+		return errors.SetError(http.StatusServiceUnavailable, "Too many WebSocket connections")
+	}
+
+	// The upgrader already answered the request.
+	h.log.Error(ctx, "error upgrading WebSocket", err.Error())
+	return nil
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	custErr "github.com/fadilahonespot/library/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// AdminAuth accepts requests carrying one of tokens as a bearer token. Every
+// request is refused when tokens is empty.
+func AdminAuth(tokens []string) echo.MiddlewareFunc {
+	return middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
+		Validator: func(key string, c echo.Context) (bool, error) {
+			for _, token := range tokens {
+				if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+		ErrorHandler: func(err error, c echo.Context) error {
+			return custErr.SetError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		},
+	})
+}
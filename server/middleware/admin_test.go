@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestAdminAuth(t *testing.T) {
+	tests := []struct {
+		name          string
+		tokens        []string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "valid token", tokens: []string{"first", "second"}, authorization: "Bearer second", wantStatus: http.StatusNoContent},
+		{name: "invalid token", tokens: []string{"first"}, authorization: "Bearer other", wantStatus: http.StatusUnauthorized},
+		{name: "missing token", tokens: []string{"first"}, wantStatus: http.StatusUnauthorized},
+		{name: "no token configured", authorization: "Bearer ", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = errorHandler
+			e.GET("/admin", func(c echo.Context) error {
+				return c.NoContent(http.StatusNoContent)
+			}, AdminAuth(tt.tokens))
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("AdminAuth() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	custErr "github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/identity"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// clientIdentityMiddleware maps the verified client certificate of a request
+// to an identity. Requests without a certificate stay anonymous, the TLS
+// handshake already refused them when certificates are required.
+func clientIdentityMiddleware(cfg config.TLSConfig, log logger.Logger) echo.MiddlewareFunc {
+	identities := make(map[string]string)
+	for _, entry := range cfg.ClientIdentities {
+		commonName, name, _ := strings.Cut(entry, "=")
+		identities[strings.TrimSpace(commonName)] = strings.TrimSpace(name)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			state := c.Request().TLS
+			if state == nil || len(state.PeerCertificates) == 0 {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			name := state.PeerCertificates[0].Subject.CommonName
+			if len(identities) > 0 {
+				mapped, ok := identities[name]
+				if !ok {
+					log.Error(ctx, "error mapping client certificate", state.PeerCertificates[0].Subject.String())
+					return custErr.SetError(http.StatusForbidden, "Client certificate is not allowed")
+				}
+				name = mapped
+			}
+
+			c.SetRequest(c.Request().WithContext(identity.WithIdentity(ctx, name)))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/identity"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+func Test_clientIdentityMiddleware(t *testing.T) {
+	tests := []struct {
+		name         string
+		identities   []string
+		commonName   string
+		wantStatus   int
+		wantIdentity string
+	}{
+		{name: "anonymous request", identities: []string{"billing-service=billing"}, wantStatus: http.StatusOK},
+		{name: "common name as identity", commonName: "billing-service", wantStatus: http.StatusOK, wantIdentity: "billing-service"},
+		{name: "mapped identity", identities: []string{"billing-service=billing"}, commonName: "billing-service", wantStatus: http.StatusOK, wantIdentity: "billing"},
+		{name: "unmapped certificate", identities: []string{"billing-service=billing"}, commonName: "intruder", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = errorHandler
+			e.Use(clientIdentityMiddleware(config.TLSConfig{ClientIdentities: tt.identities}, logger.Default()))
+			e.GET("/products", func(c echo.Context) error {
+				return c.String(http.StatusOK, identity.FromContext(c.Request().Context()))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/products", nil)
+			if tt.commonName != "" {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: tt.commonName}}}}
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("clientIdentityMiddleware() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != tt.wantIdentity {
+				t.Errorf("clientIdentityMiddleware() identity = %q, want %q", rec.Body.String(), tt.wantIdentity)
+			}
+		})
+	}
+}
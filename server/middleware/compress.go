@@ -0,0 +1,249 @@
+package middleware
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/server/openapi"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/labstack/echo/v4"
+)
+
+// encoder is implemented by the writers of every encoding, so they can be
+// pooled and reset for each response.
+type encoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+func newEncoderPools(level string) map[string]*sync.Pool {
+	gzipLevel, brotliLevel, zstdLevel := gzip.DefaultCompression, brotli.DefaultCompression, zstd.SpeedDefault
+	switch level {
+	case "fastest":
+		gzipLevel, brotliLevel, zstdLevel = gzip.BestSpeed, brotli.BestSpeed, zstd.SpeedFastest
+	case "best":
+		gzipLevel, brotliLevel, zstdLevel = gzip.BestCompression, brotli.BestCompression, zstd.SpeedBestCompression
+	}
+
+	return map[string]*sync.Pool{
+		"gzip": {New: func() interface{} {
+			w, _ := gzip.NewWriterLevel(io.Discard, gzipLevel)
+			return w
+		}},
+		"br": {New: func() interface{} {
+			return brotli.NewWriterLevel(io.Discard, brotliLevel)
+		}},
+		"zstd": {New: func() interface{} {
+			// A single goroutine per encoder, responses are compressed on the
+			// goroutine of their request.
+			w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderLevel(zstdLevel), zstd.WithEncoderConcurrency(1))
+			return w
+		}},
+	}
+}
+
+// compressMiddleware compresses responses in the encoding the client prefers
+// among cfg.Encodings. It must run outside BodyDump, which then logs the
+// uncompressed body. Streams and WebSocket connections are not compressed.
+func compressMiddleware(cfg config.CompressionConfig, docs *openapi.Builder) echo.MiddlewareFunc {
+	pools := newEncoderPools(cfg.Level)
+	contentTypes := make(map[string]bool)
+	for _, contentType := range cfg.ContentTypes {
+		contentTypes[strings.ToLower(strings.TrimSpace(contentType))] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			if isStreaming(docs, c) {
+				return next(c)
+			}
+
+			res := c.Response()
+			res.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+			encoding := acceptedEncoding(cfg.Encodings, c.Request().Header.Get(echo.HeaderAcceptEncoding))
+			if encoding == "" || c.Request().Method == http.MethodHead {
+				return next(c)
+			}
+
+			writer := &compressWriter{
+				ResponseWriter: res.Writer,
+				pool:           pools[encoding],
+				encoding:       encoding,
+				minSize:        cfg.MinSize,
+				contentTypes:   contentTypes,
+			}
+			res.Writer = writer
+			defer func() {
+				writer.close()
+				res.Writer = writer.ResponseWriter
+			}()
+
+			err = next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			return
+		}
+	}
+}
+
+// compressWriter holds the response back until MinSize bytes are written,
+// then compresses it if its content type allows.
+type compressWriter struct {
+	http.ResponseWriter
+	pool         *sync.Pool
+	encoding     string
+	minSize      int
+	contentTypes map[string]bool
+
+	status  int
+	pending []byte
+	started bool
+	encoder encoder
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.started {
+		return
+	}
+	w.status = status
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.started {
+		if w.encoder != nil {
+			return w.encoder.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.pending = append(w.pending, b...)
+	if len(w.pending) >= w.minSize {
+		err := w.start(true)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *compressWriter) Flush() {
+	if !w.started {
+		w.start(len(w.pending) >= w.minSize)
+	}
+	if w.encoder != nil {
+		w.encoder.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start writes the header, compressing the response when large is set and
+// its content type allows, followed by the pending bytes.
+func (w *compressWriter) start(large bool) error {
+	w.started = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	header := w.Header()
+	if large && w.compressible() {
+		header.Set(echo.HeaderContentEncoding, w.encoding)
+		header.Del(echo.HeaderContentLength)
+		w.encoder = w.pool.Get().(encoder)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	pending := w.pending
+	w.pending = nil
+	if len(pending) == 0 {
+		return nil
+	}
+	if w.encoder != nil {
+		_, err := w.encoder.Write(pending)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(pending)
+	return err
+}
+
+func (w *compressWriter) compressible() bool {
+	if w.status < http.StatusOK || w.status == http.StatusNoContent || w.status == http.StatusNotModified {
+		return false
+	}
+
+	header := w.Header()
+	if header.Get(echo.HeaderContentEncoding) != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get(echo.HeaderContentType))
+	return err == nil && w.contentTypes[mediaType]
+}
+
+func (w *compressWriter) close() {
+	if !w.started && (w.status != 0 || len(w.pending) > 0) {
+		w.start(false)
+	}
+	if w.encoder == nil {
+		return
+	}
+
+	w.encoder.Close()
+	w.encoder.Reset(io.Discard)
+	w.pool.Put(w.encoder)
+	w.encoder = nil
+}
+
+// acceptedEncoding returns the encoding of encodings with the highest
+// quality in acceptEncoding, earlier encodings winning ties.
+func acceptedEncoding(encodings []string, acceptEncoding string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = q
+		}
+		qualities[strings.ToLower(strings.TrimSpace(name))] = quality
+	}
+
+	candidates := make([]string, 0, len(encodings))
+	for _, encoding := range encodings {
+		if qualityOf(qualities, encoding) > 0 {
+			candidates = append(candidates, encoding)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return qualityOf(qualities, candidates[i]) > qualityOf(qualities, candidates[j])
+	})
+	return candidates[0]
+}
+
+func qualityOf(qualities map[string]float64, encoding string) float64 {
+	if quality, ok := qualities[encoding]; ok {
+		return quality
+	}
+	return qualities["*"]
+}
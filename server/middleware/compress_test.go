@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/server/handler"
+	"github.com/fadilahonespot/simple-api/server/openapi"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func decompress(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+
+	var reader io.Reader
+	switch encoding {
+	case "gzip":
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("gzip.NewReader() error = %v", err)
+		}
+		reader = r
+	case "br":
+		reader = brotli.NewReader(bytes.NewReader(body))
+	case "zstd":
+		r, err := zstd.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("zstd.NewReader() error = %v", err)
+		}
+		defer r.Close()
+		reader = r
+	default:
+		return string(body)
+	}
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("decompressing %s error = %v", encoding, err)
+	}
+	return string(content)
+}
+
+func Test_compressMiddleware(t *testing.T) {
+	cfg := config.CompressionConfig{
+		Encodings:    []string{"br", "zstd", "gzip"},
+		Level:        "default",
+		MinSize:      64,
+		ContentTypes: []string{echo.MIMEApplicationJSON, "text/csv"},
+	}
+	large := `{"data":"` + strings.Repeat("Taburan ayam gurih nikmat di setiap kemasan. ", 20) + `"}`
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		{name: "preferred encoding", path: "/json", acceptEncoding: "gzip, deflate, br, zstd", wantEncoding: "br"},
+		{name: "highest quality", path: "/json", acceptEncoding: "br;q=0.5, zstd;q=0.8, gzip;q=0.1", wantEncoding: "zstd"},
+		{name: "gzip only", path: "/json", acceptEncoding: "gzip", wantEncoding: "gzip"},
+		{name: "any encoding", path: "/json", acceptEncoding: "*", wantEncoding: "br"},
+		{name: "refused encodings", path: "/json", acceptEncoding: "br;q=0, *;q=0, identity"},
+		{name: "no Accept-Encoding", path: "/json"},
+		{name: "below minimum size", path: "/small", acceptEncoding: "gzip"},
+		{name: "content type not allowed", path: "/image", acceptEncoding: "gzip"},
+		{name: "error response", path: "/error", acceptEncoding: "gzip", wantEncoding: "gzip"},
+		{name: "event stream", path: "/events", acceptEncoding: "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dumped []byte
+			e := echo.New()
+			e.HTTPErrorHandler = func(err error, c echo.Context) {
+				if c.Response().Committed {
+					return
+				}
+				c.Blob(http.StatusBadRequest, echo.MIMEApplicationJSON, []byte(large))
+			}
+			docs := openapi.NewBuilder(openapi.Info{})
+			e.Use(compressMiddleware(cfg, docs))
+			e.Use(middleware.BodyDump(func(c echo.Context, reqBody, resBody []byte) {
+				dumped = resBody
+			}))
+
+			e.GET("/json", func(c echo.Context) error {
+				return c.Blob(http.StatusOK, echo.MIMEApplicationJSONCharsetUTF8, []byte(large))
+			})
+			e.GET("/small", func(c echo.Context) error {
+				return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, []byte(`{}`))
+			})
+			e.GET("/image", func(c echo.Context) error {
+				return c.Blob(http.StatusOK, "image/png", []byte(large))
+			})
+			e.GET("/error", func(c echo.Context) error {
+				return echo.ErrBadRequest
+			})
+			docs.Add(e.GET("/events", func(c echo.Context) error {
+				return c.Blob(http.StatusOK, handler.MIMETextEventStream, []byte(large))
+			}), openapi.Spec{Response: "", ContentType: handler.MIMETextEventStream})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set(echo.HeaderAcceptEncoding, tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get(echo.HeaderContentEncoding); got != tt.wantEncoding {
+				t.Fatalf("compressMiddleware() Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			body := decompress(t, tt.wantEncoding, rec.Body.Bytes())
+			if tt.wantEncoding != "" && body != large {
+				t.Errorf("compressMiddleware() decompressed body = %q, want %q", body, large)
+			}
+			if tt.wantEncoding != "" && string(dumped) != large {
+				t.Errorf("BodyDump body = %q, want the uncompressed body", dumped)
+			}
+		})
+	}
+}
+
+func Test_compressMiddleware_reusesEncoders(t *testing.T) {
+	cfg := config.CompressionConfig{Encodings: []string{"zstd"}, Level: "fastest", ContentTypes: []string{"text/plain"}}
+	e := echo.New()
+	e.Use(compressMiddleware(cfg, openapi.NewBuilder(openapi.Info{})))
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.QueryParam("body"))
+	})
+
+	for _, body := range []string{"first response", "second response"} {
+		req := httptest.NewRequest(http.MethodGet, "/?body="+strings.ReplaceAll(body, " ", "+"), nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, "zstd")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if got := decompress(t, "zstd", rec.Body.Bytes()); got != body {
+			t.Errorf("compressMiddleware() body = %q, want %q", got, body)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/idempotency"
+	"github.com/fadilahonespot/simple-api/utils/identity"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+var replayedHeaders = []string{echo.HeaderContentType, echo.HeaderLocation}
+
+func idempotencyMiddleware(cfg config.IdempotencyConfig, store idempotency.Store, log logger.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			request := c.Request()
+			if request.Method != http.MethodPost && request.Method != http.MethodPut {
+				return next(c)
+			}
+
+			key := request.Header.Get(cfg.Header)
+			if key == "" {
+				return next(c)
+			}
+
+			ctx := request.Context()
+			if len(key) > maxIdempotencyKeyLength {
+				log.Error(ctx, "idempotency key is too long")
+				return errors.SetError(http.StatusBadRequest, cfg.Header+" is too long")
+			}
+
+			body := []byte{}
+			if request.Body != nil {
+				body, err = io.ReadAll(request.Body)
+				if err != nil {
+					log.Error(ctx, "error reading request body", err.Error())
+					return errors.SetError(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+				}
+			}
+			request.Body = io.NopCloser(bytes.NewReader(body))
+
+			storeKey := callerKey(c) + " " + request.Method + " " + request.URL.Path + " " + key
+			fingerprint := requestFingerprint(request, body)
+
+			existing, reserved, err := store.Reserve(ctx, storeKey, fingerprint, cfg.TTL)
+			if err != nil {
+				log.Error(ctx, "error reserving idempotency key", err.Error())
+				return errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			}
+
+			if !reserved {
+				if existing.Fingerprint != fingerprint {
+					log.Error(ctx, "idempotency key reused with a different request")
+					return errors.SetError(http.StatusUnprocessableEntity, cfg.Header+" was already used with a different request")
+				}
+
+				if !existing.Completed {
+					log.Error(ctx, "idempotent request is still in progress")
+					return errors.SetError(http.StatusConflict, "A request with the same "+cfg.Header+" is still in progress")
+				}
+
+				log.Info(ctx, "Replaying idempotent response")
+				for key, values := range existing.Header {
+					c.Response().Header()[key] = values
+				}
+				c.Response().Header().Set(headerIdempotentReplayed, "true")
+				return c.Blob(existing.StatusCode, existing.Header.Get(echo.HeaderContentType), existing.Body)
+			}
+
+			completed := false
+			defer func() {
+				if !completed {
+					store.Release(ctx, storeKey)
+				}
+			}()
+
+			resBody := new(bytes.Buffer)
+			writer := &bodyCaptureWriter{
+				ResponseWriter: c.Response().Writer,
+				Writer:         io.MultiWriter(c.Response().Writer, resBody),
+			}
+			c.Response().Writer = writer
+			defer func() {
+				c.Response().Writer = writer.ResponseWriter
+			}()
+
+			err = next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				return
+			}
+
+			header := http.Header{}
+			for _, key := range replayedHeaders {
+				if value := c.Response().Header().Get(key); value != "" {
+					header.Set(key, value)
+				}
+			}
+
+			storeErr := store.Complete(ctx, storeKey, idempotency.Record{
+				StatusCode: status,
+				Header:     header,
+				Body:       resBody.Bytes(),
+			})
+			if storeErr != nil {
+				log.Error(ctx, "error storing idempotent response", storeErr.Error())
+				return
+			}
+			completed = true
+			return
+		}
+	}
+}
+
+// callerKey scopes idempotency keys to the caller so clients picking the same
+// key never get each other's response: the client identity, a hash of the
+// Authorization header or the client IP for anonymous callers.
+func callerKey(c echo.Context) string {
+	if name := identity.FromContext(c.Request().Context()); name != "" {
+		return "identity:" + name
+	}
+	if authorization := c.Request().Header.Get(echo.HeaderAuthorization); authorization != "" {
+		hash := sha256.Sum256([]byte(authorization))
+		return "authorization:" + hex.EncodeToString(hash[:])
+	}
+	return "ip:" + c.RealIP()
+}
+
+// requestFingerprint covers the Accept header as well, a stored response is
+// only replayed in the representation it was negotiated in.
+func requestFingerprint(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(request.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(request.URL.Path))
+	hash.Write([]byte{0})
+	hash.Write([]byte(request.Header.Get(echo.HeaderAccept)))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type bodyCaptureWriter struct {
+	http.ResponseWriter
+	io.Writer
+}
+
+func (w *bodyCaptureWriter) WriteHeader(code int) {
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	return w.Writer.Write(b)
+}
+
+func (w *bodyCaptureWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/idempotency"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+func Test_idempotencyMiddleware(t *testing.T) {
+	logger.NewLogger(config.LoggerConfig{})
+
+	cfg := config.IdempotencyConfig{
+		Enabled: true,
+		Header:  "Idempotency-Key",
+		TTL:     time.Hour,
+	}
+
+	type call struct {
+		key           string
+		authorization string
+		accept        string
+		body          string
+		wantStatus    int
+		wantReplay    bool
+	}
+	tests := []struct {
+		name          string
+		handlerStatus int
+		inFlight      bool
+		calls         []call
+		wantHandled   int
+	}{
+		{
+			name:          "replay response for retried request",
+			handlerStatus: http.StatusOK,
+			calls: []call{
+				{key: "key-1", body: `{"title":"a"}`, wantStatus: http.StatusOK},
+				{key: "key-1", body: `{"title":"a"}`, wantStatus: http.StatusOK, wantReplay: true},
+			},
+			wantHandled: 1,
+		},
+		{
+			name:          "reject reused key with different body",
+			handlerStatus: http.StatusOK,
+			calls: []call{
+				{key: "key-1", body: `{"title":"a"}`, wantStatus: http.StatusOK},
+				{key: "key-1", body: `{"title":"b"}`, wantStatus: http.StatusUnprocessableEntity},
+			},
+			wantHandled: 1,
+		},
+		{
+			name:          "reject reused key with different accept",
+			handlerStatus: http.StatusOK,
+			calls: []call{
+				{key: "key-1", accept: echo.MIMEApplicationJSON, body: `{"title":"a"}`, wantStatus: http.StatusOK},
+				{key: "key-1", accept: echo.MIMEApplicationXML, body: `{"title":"a"}`, wantStatus: http.StatusUnprocessableEntity},
+			},
+			wantHandled: 1,
+		},
+		{
+			name:          "scope keys to the caller",
+			handlerStatus: http.StatusOK,
+			calls: []call{
+				{key: "key-1", authorization: "Bearer alice", body: `{"title":"a"}`, wantStatus: http.StatusOK},
+				{key: "key-1", authorization: "Bearer bob", body: `{"title":"a"}`, wantStatus: http.StatusOK},
+				{key: "key-1", body: `{"title":"a"}`, wantStatus: http.StatusOK},
+				{key: "key-1", authorization: "Bearer alice", body: `{"title":"a"}`, wantStatus: http.StatusOK, wantReplay: true},
+			},
+			wantHandled: 3,
+		},
+		{
+			name:          "reject duplicate while in flight",
+			handlerStatus: http.StatusOK,
+			inFlight:      true,
+			calls: []call{
+				{key: "key-1", body: `{"title":"a"}`, wantStatus: http.StatusConflict},
+			},
+			wantHandled: 0,
+		},
+		{
+			name:          "do not store server errors",
+			handlerStatus: http.StatusInternalServerError,
+			calls: []call{
+				{key: "key-1", body: `{"title":"a"}`, wantStatus: http.StatusInternalServerError},
+				{key: "key-1", body: `{"title":"a"}`, wantStatus: http.StatusInternalServerError},
+			},
+			wantHandled: 2,
+		},
+		{
+			name:          "skip requests without key",
+			handlerStatus: http.StatusOK,
+			calls: []call{
+				{body: `{"title":"a"}`, wantStatus: http.StatusOK},
+				{body: `{"title":"a"}`, wantStatus: http.StatusOK},
+			},
+			wantHandled: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := idempotency.NewMemoryStore()
+			if tt.inFlight {
+				body := `{"title":"a"}`
+				req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+				store.Reserve(context.TODO(), "ip:192.0.2.1 POST /products key-1", requestFingerprint(req, []byte(body)), time.Hour)
+			}
+
+			handled := 0
+			e := echo.New()
+			e.HTTPErrorHandler = errorHandler
+			e.Use(idempotencyMiddleware(cfg, store, logger.Default()))
+			e.POST("/products", func(c echo.Context) error {
+				handled++
+				return c.JSON(tt.handlerStatus, map[string]int{"call": handled})
+			})
+
+			var firstBody string
+			for i, call := range tt.calls {
+				req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(call.body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				if call.key != "" {
+					req.Header.Set(cfg.Header, call.key)
+				}
+				if call.authorization != "" {
+					req.Header.Set(echo.HeaderAuthorization, call.authorization)
+				}
+				if call.accept != "" {
+					req.Header.Set(echo.HeaderAccept, call.accept)
+				}
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+
+				if rec.Code != call.wantStatus {
+					t.Errorf("call %d status = %v, want %v", i, rec.Code, call.wantStatus)
+				}
+
+				replayed := rec.Header().Get(headerIdempotentReplayed) == "true"
+				if replayed != call.wantReplay {
+					t.Errorf("call %d replayed = %v, want %v", i, replayed, call.wantReplay)
+				}
+
+				if i == 0 {
+					firstBody = rec.Body.String()
+				} else if call.wantReplay && rec.Body.String() != firstBody {
+					t.Errorf("call %d body = %v, want %v", i, rec.Body.String(), firstBody)
+				}
+			}
+
+			if handled != tt.wantHandled {
+				t.Errorf("handler called %v times, want %v", handled, tt.wantHandled)
+			}
+		})
+	}
+}
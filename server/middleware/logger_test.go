@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	custErr "github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/library/logres"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/server/openapi"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/fadilahonespot/simple-api/utils/redact"
+	"github.com/labstack/echo/v4"
+)
+
+var testRedactor = redact.NewRedactor(config.RedactionConfig{
+	Headers:     []string{"Authorization", "Cookie"},
+	Fields:      []string{"access_token"},
+	MaxBodySize: 4096,
+})
+
+func Test_setLoggerMiddleware_redaction(t *testing.T) {
+	var ctxLogger logres.Context
+	e := echo.New()
+	e.Use(setLoggerMiddleware(7690, defaultRequestIDConfig, testRedactor, logger.Default()))
+	e.GET("/ws", func(c echo.Context) error {
+		ctxLogger = logres.GetCtxLogger(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ws?access_token=secret&page=1", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Cookie", "session=secret")
+	req.Header.Set("Accept", "application/json")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	header, _ := ctxLogger.Header.(http.Header)
+	if got := header.Get("Authorization"); got != redact.Mask {
+		t.Errorf("Authorization = %q, want masked", got)
+	}
+	if got := header.Get("Cookie"); got != redact.Mask {
+		t.Errorf("Cookie = %q, want masked", got)
+	}
+	if got := header.Get("Accept"); got != "application/json" {
+		t.Errorf("Accept = %q, want it unchanged", got)
+	}
+	if want := "/ws?access_token=%2A%2A%2A%2A%2A%2A&page=1"; ctxLogger.ReqURI != want {
+		t.Errorf("ReqURI = %q, want %q", ctxLogger.ReqURI, want)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("request Authorization = %q, want it untouched", got)
+	}
+}
+
+func Test_loggerMiddleware_bodyLimit(t *testing.T) {
+	recorder := logger.NewRecorder()
+	e := echo.New()
+	e.HTTPErrorHandler = errorHandler
+	e.Use(bodyLimitMiddleware(config.SecurityConfig{MaxBodySize: 10}))
+	e.Use(loggerMiddleware(openapi.NewBuilder(openapi.Info{}), testRedactor, nil, logger.New(recorder, nil)))
+	e.POST("/products", func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return custErr.SetError(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		}
+		return c.String(http.StatusOK, string(body))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader("0123456789a"))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+	if tdrs := recorder.TDRs(); len(tdrs) != 1 {
+		t.Errorf("TDRs = %d, want the refused request logged once", len(tdrs))
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/simple-api/utils/metrics"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func Test_metricsMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    echo.HandlerFunc
+		wantStatus string
+	}{
+		{
+			name: "success request",
+			handler: func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			},
+			wantStatus: "200",
+		},
+		{
+			name: "application error",
+			handler: func(c echo.Context) error {
+				return errors.SetError(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+			},
+			wantStatus: "404",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = errorHandler
+			e.Use(metricsMiddleware())
+			e.GET("/products/:productId", tt.handler)
+
+			counter := metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, "/products/:productId", tt.wantStatus)
+			before := testutil.ToFloat64(counter)
+
+			req := httptest.NewRequest(http.MethodGet, "/products/a1b91cb9-c4a5-408f-ad28-5f32e197d954", nil)
+			e.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("metricsMiddleware() requests_total delta = %v, want 1", got)
+			}
+		})
+	}
+}
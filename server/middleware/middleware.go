@@ -1,60 +1,140 @@
 package middleware
 
 import (
-	"context"
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"os"
 
 	custErr "github.com/fadilahonespot/library/errors"
 	"github.com/fadilahonespot/library/logres"
+	"github.com/fadilahonespot/library/response"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/server/handler"
+	"github.com/fadilahonespot/simple-api/server/openapi"
+	"github.com/fadilahonespot/simple-api/server/render"
+	"github.com/fadilahonespot/simple-api/utils/idempotency"
 	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/fadilahonespot/simple-api/utils/redact"
+	"github.com/fadilahonespot/simple-api/utils/requestid"
+	"github.com/fadilahonespot/simple-api/utils/tracing"
 	"github.com/go-playground/validator"
-	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"github.com/spf13/cast"
-	"github.com/fadilahonespot/library/response"
 )
 
-func SetupMiddleware(server *echo.Echo) {
-	server.Use(setLoggerMiddleware())
-	server.Use(loggerMiddleware())
+func SetupMiddleware(server *echo.Echo, cfg config.Config, docs *openapi.Builder, idempotencyStore idempotency.Store, log logger.Logger) {
+	server.Use(metricsMiddleware())
+	server.Use(tracingMiddleware())
+	redactor := redact.NewRedactor(cfg.Redaction)
+
+	server.Use(setLoggerMiddleware(cfg.App.Port, cfg.RequestID, redactor, log))
+	server.Use(recoverMiddleware(cfg.Recovery, log))
+	if cfg.TLS.Enabled() && cfg.TLS.ClientAuth != "none" {
+		server.Use(clientIdentityMiddleware(cfg.TLS, log))
+	}
+	server.Use(securityHeadersMiddleware(cfg.Security, docs))
+	if len(cfg.CORS.AllowOrigins) > 0 || len(cfg.CORS.CredentialOrigins) > 0 {
+		server.Use(corsMiddleware(cfg.CORS))
+	}
+	server.Use(bodyLimitMiddleware(cfg.Security))
+	if cfg.Compression.Enabled {
+		server.Use(compressMiddleware(cfg.Compression, docs))
+	}
+	server.Use(loggerMiddleware(docs, redactor, cfg.Redaction.SkipBodyRoutes, log))
+	server.Use(readYourWritesMiddleware(cfg.Database.ReadYourWritesHeader))
+	if cfg.OpenAPI.ValidateRequests || cfg.OpenAPI.ValidateResponses {
+		server.Use(openAPIValidationMiddleware(cfg.OpenAPI, docs, log))
+	}
+	if cfg.Idempotency.Enabled {
+		server.Use(idempotencyMiddleware(cfg.Idempotency, idempotencyStore, log))
+	}
 
 	server.HTTPErrorHandler = errorHandler
 	server.Validator = &DataValidator{ValidatorData: validator.New()}
 }
 
-func setLoggerMiddleware() echo.MiddlewareFunc {
+func setLoggerMiddleware(port int, requestIDConfig config.RequestIDConfig, redactor redact.Redactor, log logger.Logger) echo.MiddlewareFunc {
+	// requestLog is sampled since it writes an entry for every request.
+	requestLog := log.Sampled()
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			threadID := requestid.Resolve(c.Request().Context(), requestIDConfig, c.Request().Header.Get)
+			if requestIDConfig.ResponseHeader != "" {
+				c.Response().Header().Set(requestIDConfig.ResponseHeader, threadID)
+			}
 
 			ctxLogger := logres.Context{
-				ServiceName:    "simple-api",
-				ServiceVersion: "1.0.0",
-				ServicePort:    cast.ToInt(os.Getenv("APP_PORT")),
-				ThreadID:       uuid.New().String(),
+				ServiceName:    tracing.ServiceName,
+				ServiceVersion: tracing.ServiceVersion,
+				ServicePort:    port,
+				ThreadID:       threadID,
 				ReqMethod:      c.Request().Method,
-				ReqURI:         c.Request().URL.String(),
-				Header:         c.Request().Header,
+				ReqURI:         redactor.URL(c.Request().URL),
+				Header:         redactor.Header(c.Request().Header),
 			}
 
 			request := c.Request()
-			ctx := logres.SetCtxLogger(context.Background(), ctxLogger)
+			ctx := logres.SetCtxLogger(request.Context(), ctxLogger)
 			c.SetRequest(request.WithContext(ctx))
 
-			logger.Info(ctx, "Incoming Request")
+			requestLog.Info(ctx, "Incoming Request")
 
 			return next(c)
 		}
 	}
 }
 
-func loggerMiddleware() echo.MiddlewareFunc {
-	return middleware.BodyDump(func(c echo.Context, reqBody, resBody []byte) {
-		logger.TDR(c.Request().Context(), reqBody, resBody)
+func loggerMiddleware(docs *openapi.Builder, redactor redact.Redactor, skipBodyRoutes []string, log logger.Logger) echo.MiddlewareFunc {
+	skipBody := routeSet(skipBodyRoutes)
+
+	dump := middleware.BodyDumpWithConfig(middleware.BodyDumpConfig{
+		Skipper: func(c echo.Context) bool {
+			return isStreaming(docs, c)
+		},
+		Handler: func(c echo.Context, reqBody, resBody []byte) {
+			ctx := c.Request().Context()
+			if skipBody[routeKey(c)] {
+				log.TDR(ctx, nil, nil)
+				return
+			}
+
+			reqBody = redactor.Body(c.Request().Header.Get(echo.HeaderContentType), reqBody)
+			resBody = redactor.Body(c.Response().Header().Get(echo.HeaderContentType), resBody)
+			log.TDR(ctx, reqBody, resBody)
+		},
 	})
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		dumpNext := dump(next)
+		return func(c echo.Context) error {
+			// The body dump ignores read errors, so the body is read first to
+			// refuse one over the limit of bodyLimitMiddleware.
+			req := c.Request()
+			if req.Body != nil && !isStreaming(docs, c) {
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					return dump(func(c echo.Context) error {
+						return bodyReadError(err)
+					})(c)
+				}
+				req.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			return dumpNext(c)
+		}
+	}
+}
+
+// isStreaming reports whether the request is upgraded to a WebSocket or the
+// route streams its response, neither of which may be buffered.
+func isStreaming(docs *openapi.Builder, c echo.Context) bool {
+	if c.IsWebSocket() {
+		return true
+	}
+	return docs.Document().Operation(c.Request().Method, c.Path()).Produces(handler.MIMETextEventStream)
 }
 
 func errorHandler(err error, c echo.Context) {
@@ -67,16 +147,35 @@ func errorHandler(err error, c echo.Context) {
 	code := http.StatusInternalServerError
 	resp := response.ResponseError(code, "general error")
 
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		err = bodyReadError(err)
+	}
+
 	if he, ok := err.(*custErr.ApplicationError); ok {
 		resp.Code = he.ErrorCode
 		resp.Message = he.Error()
 	}
 
+	if ce, ok := err.(*contractError); ok {
+		resp.Code = http.StatusBadRequest
+		resp.Message = "Request does not match the API contract"
+		resp.Data = ce.violations
+	}
+
 	request := c.Request()
 	ctx := logres.SetErrorMessage(c.Request().Context(), err.Error())
 	c.SetRequest(request.WithContext(ctx))
 
-	c.JSON(resp.Code, resp)
+	render.Render(c, resp.Code, errorResponse{
+		Response:  resp,
+		RequestID: logres.GetCtxLogger(ctx).ThreadID,
+	})
+}
+
+type errorResponse struct {
+	response.Response
+	RequestID string `json:"requestId,omitempty"`
 }
 
 type DataValidator struct {
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/server/openapi"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// contractError rejects a request that does not match the OpenAPI document.
+type contractError struct {
+	violations []openapi.Violation
+}
+
+func (e *contractError) Error() string {
+	messages := make([]string, 0, len(e.violations))
+	for _, violation := range e.violations {
+		messages = append(messages, violation.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func openAPIValidationMiddleware(cfg config.OpenAPIConfig, docs *openapi.Builder, log logger.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			doc := docs.Document()
+			operation := doc.Operation(c.Request().Method, c.Path())
+			if operation == nil {
+				return next(c)
+			}
+
+			request := c.Request()
+			ctx := request.Context()
+			if cfg.ValidateRequests {
+				body := []byte{}
+				if operation.RequestBody != nil && request.Body != nil {
+					body, err = io.ReadAll(request.Body)
+					if err != nil {
+						log.Error(ctx, "error reading request body", err.Error())
+						return &contractError{violations: []openapi.Violation{{In: "body", Message: "request body could not be read"}}}
+					}
+					request.Body = io.NopCloser(bytes.NewReader(body))
+				}
+
+				if violations := doc.ValidateRequest(operation, c, body); len(violations) > 0 {
+					log.Error(ctx, "request does not match the API contract", (&contractError{violations: violations}).Error())
+					return &contractError{violations: violations}
+				}
+			}
+
+			if !cfg.ValidateResponses || isStreaming(docs, c) {
+				return next(c)
+			}
+
+			resBody := new(bytes.Buffer)
+			writer := &bodyCaptureWriter{
+				ResponseWriter: c.Response().Writer,
+				Writer:         io.MultiWriter(c.Response().Writer, resBody),
+			}
+			c.Response().Writer = writer
+			defer func() {
+				c.Response().Writer = writer.ResponseWriter
+			}()
+
+			err = next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			response := c.Response()
+			contentType := response.Header().Get(echo.HeaderContentType)
+			var violations []openapi.Violation
+			if fields := selectedFields(c.QueryParam("fields")); len(fields) > 0 {
+				violations = doc.ValidateSparseResponse(operation, response.Status, contentType, resBody.Bytes(), fields)
+			} else {
+				violations = doc.ValidateResponse(operation, response.Status, contentType, resBody.Bytes())
+			}
+			if len(violations) > 0 {
+				log.Error(ctx, "response does not match the API contract", (&contractError{violations: violations}).Error())
+			}
+			return
+		}
+	}
+}
+
+// selectedFields returns the fields of a ?fields= list.
+func selectedFields(list string) (fields []string) {
+	for _, field := range strings.Split(list, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/server/openapi"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type validationRequest struct {
+	Title  string  `json:"title" validate:"required"`
+	Rating float64 `json:"rating" validate:"min=0,max=5"`
+}
+
+func Test_openAPIValidationMiddleware(t *testing.T) {
+	logger.NewLogger(config.LoggerConfig{})
+
+	const productId = "5b0e1c44-9a9d-4d2b-8f7e-4f1f8a6f8a11"
+	tests := []struct {
+		name           string
+		cfg            config.OpenAPIConfig
+		method         string
+		target         string
+		contentType    string
+		body           string
+		wantStatus     int
+		wantViolations []string
+	}{
+		{
+			name:       "valid request",
+			cfg:        config.OpenAPIConfig{ValidateRequests: true},
+			method:     http.MethodPut,
+			target:     "/items/" + productId + "?page=1",
+			body:       `{"title":"a","rating":4.5}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid path param",
+			cfg:            config.OpenAPIConfig{ValidateRequests: true},
+			method:         http.MethodPut,
+			target:         "/items/not-a-uuid",
+			body:           `{"title":"a"}`,
+			wantStatus:     http.StatusBadRequest,
+			wantViolations: []string{"path productId: must be a valid UUID"},
+		},
+		{
+			name:           "invalid query param",
+			cfg:            config.OpenAPIConfig{ValidateRequests: true},
+			method:         http.MethodPut,
+			target:         "/items/" + productId + "?page=abc",
+			body:           `{"title":"a"}`,
+			wantStatus:     http.StatusBadRequest,
+			wantViolations: []string{"query page: must be an integer"},
+		},
+		{
+			name:           "invalid body",
+			cfg:            config.OpenAPIConfig{ValidateRequests: true},
+			method:         http.MethodPut,
+			target:         "/items/" + productId,
+			body:           `{"rating":6}`,
+			wantStatus:     http.StatusBadRequest,
+			wantViolations: []string{"body title: is required", "body rating: must be less than or equal to 5"},
+		},
+		{
+			name:           "malformed body",
+			cfg:            config.OpenAPIConfig{ValidateRequests: true},
+			method:         http.MethodPut,
+			target:         "/items/" + productId,
+			body:           `{"title":`,
+			wantStatus:     http.StatusBadRequest,
+			wantViolations: []string{"body: request body is not valid JSON"},
+		},
+		{
+			name:        "xml body",
+			cfg:         config.OpenAPIConfig{ValidateRequests: true},
+			method:      http.MethodPut,
+			target:      "/items/" + productId,
+			contentType: echo.MIMEApplicationXML,
+			body:        `<item><title>a</title></item>`,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:           "structured json body",
+			cfg:            config.OpenAPIConfig{ValidateRequests: true},
+			method:         http.MethodPut,
+			target:         "/items/" + productId,
+			contentType:    "application/vnd.simple-api.v2+json",
+			body:           `{"rating":6}`,
+			wantStatus:     http.StatusBadRequest,
+			wantViolations: []string{"body title: is required", "body rating: must be less than or equal to 5"},
+		},
+		{
+			name:       "skip request validation when disabled",
+			cfg:        config.OpenAPIConfig{ValidateResponses: true},
+			method:     http.MethodPut,
+			target:     "/items/not-a-uuid",
+			body:       `{"rating":6}`,
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = errorHandler
+			docs := openapi.NewBuilder(openapi.Info{Title: "test", Version: "1"})
+			e.Use(openAPIValidationMiddleware(tt.cfg, docs, logger.Default()))
+
+			handled := false
+			docs.Add(e.PUT("/items/:productId", func(c echo.Context) error {
+				handled = true
+				return c.JSON(http.StatusOK, map[string]string{"id": c.Param("productId")})
+			}), openapi.Spec{
+				Parameters: []openapi.Parameter{
+					openapi.PathParam("productId", "", &openapi.Schema{Type: "string", Format: "uuid"}),
+					openapi.QueryParam("page", "", &openapi.Schema{Type: "integer"}),
+				},
+				Consumes: []string{echo.MIMEApplicationXML},
+				Request:  validationRequest{},
+				Response: map[string]string{},
+			})
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = echo.MIMEApplicationJSON
+			}
+			req.Header.Set(echo.HeaderContentType, contentType)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("openAPIValidationMiddleware() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if handled != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("openAPIValidationMiddleware() handled = %v", handled)
+			}
+			if len(tt.wantViolations) == 0 {
+				return
+			}
+
+			var resp struct {
+				Data []openapi.Violation `json:"data"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			got := make(map[string]bool)
+			for _, violation := range resp.Data {
+				got[violation.String()] = true
+			}
+			if len(got) != len(tt.wantViolations) {
+				t.Errorf("openAPIValidationMiddleware() violations = %v, want %v", resp.Data, tt.wantViolations)
+			}
+			for _, want := range tt.wantViolations {
+				if !got[want] {
+					t.Errorf("openAPIValidationMiddleware() missing violation %q in %v", want, resp.Data)
+				}
+			}
+		})
+	}
+}
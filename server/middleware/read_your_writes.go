@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/fadilahonespot/simple-api/utils/database"
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/cast"
+)
+
+func readYourWritesMiddleware(header string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if header != "" && cast.ToBool(c.Request().Header.Get(header)) {
+				request := c.Request()
+				c.SetRequest(request.WithContext(database.WithPrimary(request.Context())))
+			}
+
+			return next(c)
+		}
+	}
+}
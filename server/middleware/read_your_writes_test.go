@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fadilahonespot/simple-api/utils/database"
+	"github.com/labstack/echo/v4"
+)
+
+func Test_readYourWritesMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		headerValue    string
+		wantUsePrimary bool
+	}{
+		{
+			name:           "read from replica by default",
+			wantUsePrimary: false,
+		},
+		{
+			name:           "read from primary when requested",
+			headerValue:    "true",
+			wantUsePrimary: true,
+		},
+		{
+			name:           "ignore invalid header value",
+			headerValue:    "yes please",
+			wantUsePrimary: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var usePrimary bool
+			e := echo.New()
+			e.Use(readYourWritesMiddleware("X-Read-Your-Writes"))
+			e.GET("/products", func(c echo.Context) error {
+				usePrimary = database.UsePrimary(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/products", nil)
+			if tt.headerValue != "" {
+				req.Header.Set("X-Read-Your-Writes", tt.headerValue)
+			}
+			e.ServeHTTP(httptest.NewRecorder(), req)
+
+			if usePrimary != tt.wantUsePrimary {
+				t.Errorf("readYourWritesMiddleware() use primary = %v, want %v", usePrimary, tt.wantUsePrimary)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"github.com/fadilahonespot/library/logres"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/identity"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/fadilahonespot/simple-api/utils/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+const crashDumpPattern = "crash-*.json"
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+type crashReport struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestId"`
+	Method    string    `json:"method"`
+	URI       string    `json:"uri"`
+	Route     string    `json:"route"`
+	RemoteIP  string    `json:"remoteIp"`
+	Identity  string    `json:"identity,omitempty"`
+	Panic     string    `json:"panic"`
+	Stack     string    `json:"stack"`
+}
+
+// recoverMiddleware turns a panic below it into the standard 500 response.
+// It must run after setLoggerMiddleware so the report carries the thread ID.
+func recoverMiddleware(cfg config.RecoveryConfig, log logger.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				ctx := c.Request().Context()
+				report := crashReport{
+					Time:      time.Now().UTC(),
+					RequestID: logres.GetCtxLogger(ctx).ThreadID,
+					Method:    c.Request().Method,
+					URI:       logres.GetCtxLogger(ctx).ReqURI,
+					Route:     c.Path(),
+					RemoteIP:  c.RealIP(),
+					Identity:  identity.FromContext(ctx),
+					Panic:     fmt.Sprint(recovered),
+					Stack:     string(debug.Stack()),
+				}
+				log.Error(ctx, "Recovered from panic", report)
+				metrics.HTTPPanicsTotal.WithLabelValues(report.Method, report.Route).Inc()
+
+				if cfg.DumpFolder != "" {
+					dumpErr := writeCrashDump(cfg.DumpFolder, cfg.MaxDumps, report)
+					if dumpErr != nil {
+						log.Error(ctx, "error writing crash dump", dumpErr.Error())
+					}
+				}
+
+				if c.Response().Committed {
+					// Part of the response is already sent, there is no way to report the error.
+					return
+				}
+				err = fmt.Errorf("panic: %v", recovered)
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+func writeCrashDump(folder string, maxDumps int, report crashReport) error {
+	err := os.MkdirAll(folder, 0o755)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("crash-%s-%s.json", report.Time.Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(report.RequestID, ""))
+	err = os.WriteFile(filepath.Join(folder, name), content, 0o600)
+	if err != nil {
+		return err
+	}
+
+	dumps, err := filepath.Glob(filepath.Join(folder, crashDumpPattern))
+	if err != nil || len(dumps) <= maxDumps {
+		return err
+	}
+
+	// Names start with the time, so the oldest sort first.
+	sort.Strings(dumps)
+	for _, dump := range dumps[:len(dumps)-maxDumps] {
+		os.Remove(dump)
+	}
+	return nil
+}
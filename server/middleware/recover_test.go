@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+func Test_recoverMiddleware(t *testing.T) {
+	logger.NewLogger(config.LoggerConfig{})
+
+	tests := []struct {
+		name       string
+		handler    echo.HandlerFunc
+		maxDumps   int
+		requests   int
+		wantStatus int
+		wantDumps  int
+	}{
+		{
+			name: "no panic",
+			handler: func(c echo.Context) error {
+				return c.NoContent(http.StatusNoContent)
+			},
+			maxDumps:   10,
+			requests:   1,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "nil dereference",
+			handler: func(c echo.Context) error {
+				var data *struct{ Title string }
+				return c.String(http.StatusOK, data.Title)
+			},
+			maxDumps:   10,
+			requests:   1,
+			wantStatus: http.StatusInternalServerError,
+			wantDumps:  1,
+		},
+		{
+			name: "keep the most recent dumps",
+			handler: func(c echo.Context) error {
+				panic("boom")
+			},
+			maxDumps:   2,
+			requests:   3,
+			wantStatus: http.StatusInternalServerError,
+			wantDumps:  2,
+		},
+		{
+			name: "panic after the response is committed",
+			handler: func(c echo.Context) error {
+				c.String(http.StatusAccepted, "partial")
+				panic("boom")
+			},
+			maxDumps:   10,
+			requests:   1,
+			wantStatus: http.StatusAccepted,
+			wantDumps:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+			e := echo.New()
+			e.HTTPErrorHandler = errorHandler
+			e.Use(setLoggerMiddleware(0, config.RequestIDConfig{InboundHeaders: []string{"X-Request-ID"}, TrustInbound: true}, testRedactor, logger.Default()))
+			recorder := logger.NewRecorder()
+			e.Use(recoverMiddleware(config.RecoveryConfig{DumpFolder: folder, MaxDumps: tt.maxDumps}, logger.New(recorder, nil)))
+			e.GET("/products/:productId", tt.handler)
+
+			var rec *httptest.ResponseRecorder
+			for i := 0; i < tt.requests; i++ {
+				req := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+				req.Header.Set("X-Request-ID", "../req-1")
+				rec = httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusInternalServerError {
+				var body errorResponse
+				json.Unmarshal(rec.Body.Bytes(), &body)
+				if body.Code != http.StatusInternalServerError || body.RequestID != "../req-1" {
+					t.Errorf("body = %s, want the standard error response", rec.Body.String())
+				}
+			}
+
+			if panicked := tt.wantStatus != http.StatusNoContent; recorder.Has(logger.LevelError, "Recovered from panic") != panicked {
+				t.Errorf("logs = %+v, want the recovered panic logged: %v", recorder.Entries(), panicked)
+			}
+
+			dumps, _ := filepath.Glob(filepath.Join(folder, crashDumpPattern))
+			if len(dumps) != tt.wantDumps {
+				t.Fatalf("crash dumps = %d, want %d", len(dumps), tt.wantDumps)
+			}
+			for _, dump := range dumps {
+				content, _ := os.ReadFile(dump)
+				var report crashReport
+				json.Unmarshal(content, &report)
+				if report.RequestID != "../req-1" || report.Route != "/products/:productId" || report.Stack == "" {
+					t.Errorf("crash report = %+v, want request ID, route and stack", report)
+				}
+			}
+		})
+	}
+}
+
+func Test_recoverMiddleware_abortHandler(t *testing.T) {
+	e := echo.New()
+	e.Use(recoverMiddleware(config.RecoveryConfig{}, logger.Default()))
+	e.GET("/", func(c echo.Context) error {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler to propagate", recovered)
+		}
+	}()
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/library/logres"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+var defaultRequestIDConfig = config.RequestIDConfig{
+	InboundHeaders: []string{echo.HeaderXRequestID, echo.HeaderXCorrelationID},
+	ResponseHeader: echo.HeaderXRequestID,
+	TrustInbound:   true,
+}
+
+func Test_setLoggerMiddleware_requestID(t *testing.T) {
+	logger.NewLogger(config.LoggerConfig{})
+
+	tests := []struct {
+		name            string
+		requestIDConfig config.RequestIDConfig
+		headers         map[string]string
+		handlerErr      error
+		wantID          string
+		wantInBody      bool
+		wantGenerate    bool
+	}{
+		{
+			name:            "use inbound request id",
+			requestIDConfig: defaultRequestIDConfig,
+			headers:         map[string]string{echo.HeaderXRequestID: "req-123"},
+			wantID:          "req-123",
+		},
+		{
+			name:            "use inbound correlation id",
+			requestIDConfig: defaultRequestIDConfig,
+			headers:         map[string]string{echo.HeaderXCorrelationID: "corr-456"},
+			wantID:          "corr-456",
+		},
+		{
+			name:            "reject invalid inbound id",
+			requestIDConfig: defaultRequestIDConfig,
+			headers:         map[string]string{echo.HeaderXRequestID: "bad id\n" + strings.Repeat("x", 200)},
+			wantGenerate:    true,
+		},
+		{
+			name: "ignore inbound id when not trusted",
+			requestIDConfig: config.RequestIDConfig{
+				InboundHeaders: []string{echo.HeaderXRequestID},
+				ResponseHeader: echo.HeaderXRequestID,
+			},
+			headers:      map[string]string{echo.HeaderXRequestID: "req-123"},
+			wantGenerate: true,
+		},
+		{
+			name:            "echo request id in error body",
+			requestIDConfig: defaultRequestIDConfig,
+			headers:         map[string]string{echo.HeaderXRequestID: "req-789"},
+			handlerErr:      errors.SetError(http.StatusNotFound, http.StatusText(http.StatusNotFound)),
+			wantID:          "req-789",
+			wantInBody:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctx context.Context
+			e := echo.New()
+			e.HTTPErrorHandler = errorHandler
+			e.Use(setLoggerMiddleware(7690, tt.requestIDConfig, testRedactor, logger.Default()))
+			e.GET("/products", func(c echo.Context) error {
+				ctx = c.Request().Context()
+				if tt.handlerErr != nil {
+					return tt.handlerErr
+				}
+				return c.NoContent(http.StatusOK)
+			})
+
+			reqCtx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req := httptest.NewRequest(http.MethodGet, "/products", nil).WithContext(reqCtx)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			gotID := rec.Header().Get(echo.HeaderXRequestID)
+			if tt.wantGenerate {
+				if gotID == "" || gotID == tt.headers[echo.HeaderXRequestID] {
+					t.Errorf("setLoggerMiddleware() request id = %v, want generated id", gotID)
+				}
+			} else if gotID != tt.wantID {
+				t.Errorf("setLoggerMiddleware() request id = %v, want %v", gotID, tt.wantID)
+			}
+
+			if threadID := logres.GetCtxLogger(ctx).ThreadID; threadID != gotID {
+				t.Errorf("setLoggerMiddleware() thread id = %v, want %v", threadID, gotID)
+			}
+
+			if ctx.Err() == nil {
+				t.Errorf("setLoggerMiddleware() context is not cancelled with the request context")
+			}
+
+			if tt.wantInBody {
+				var body errorResponse
+				json.Unmarshal(rec.Body.Bytes(), &body)
+				if body.RequestID != tt.wantID {
+					t.Errorf("errorHandler() requestId = %v, want %v", body.RequestID, tt.wantID)
+				}
+			}
+		})
+	}
+}
+
+func Test_setLoggerMiddleware_defaultConfig(t *testing.T) {
+	logger.NewLogger(config.LoggerConfig{})
+	t.Setenv("DB_USERNAME", "root")
+	t.Setenv("DB_HOST", "localhost")
+	t.Setenv("DB_NAME", "simple_api")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+
+	e := echo.New()
+	e.Use(setLoggerMiddleware(cfg.App.Port, cfg.RequestID, testRedactor, logger.Default()))
+	e.GET("/products", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-123")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(echo.HeaderXRequestID); got != "req-123" {
+		t.Errorf("setLoggerMiddleware() request id = %v, want req-123", got)
+	}
+}
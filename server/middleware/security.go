@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	custErr "github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/server/openapi"
+	"github.com/labstack/echo/v4"
+)
+
+func securityHeadersMiddleware(cfg config.SecurityConfig, docs *openapi.Builder) echo.MiddlewareFunc {
+	var hsts string
+	if cfg.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int64(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+			if cfg.FrameOptions != "" {
+				header.Set(echo.HeaderXFrameOptions, cfg.FrameOptions)
+			}
+			if cfg.ReferrerPolicy != "" {
+				header.Set(echo.HeaderReferrerPolicy, cfg.ReferrerPolicy)
+			}
+
+			csp := cfg.ContentSecurityPolicy
+			if docs.Document().Operation(c.Request().Method, c.Path()).Produces(echo.MIMETextHTML) {
+				csp = cfg.DocsContentSecurityPolicy
+			}
+			if csp != "" {
+				header.Set(echo.HeaderContentSecurityPolicy, csp)
+			}
+
+			if hsts != "" && c.Scheme() == "https" {
+				header.Set(echo.HeaderStrictTransportSecurity, hsts)
+			}
+			return next(c)
+		}
+	}
+}
+
+// corsMiddleware answers preflight requests itself. Requests from other
+// origins are served without CORS headers, so the browser hides the response.
+func corsMiddleware(cfg config.CORSConfig) echo.MiddlewareFunc {
+	credentials := make(map[string]bool)
+	for _, origin := range cfg.CredentialOrigins {
+		credentials[strings.ToLower(origin)] = true
+	}
+	allowMethods := strings.Join(cfg.AllowMethods, ",")
+	allowHeaders := strings.Join(cfg.AllowHeaders, ",")
+	exposeHeaders := strings.Join(cfg.ExposeHeaders, ",")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			origin := c.Request().Header.Get(echo.HeaderOrigin)
+			if origin == "" {
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Add(echo.HeaderVary, echo.HeaderOrigin)
+			preflight := c.Request().Method == http.MethodOptions && c.Request().Header.Get(echo.HeaderAccessControlRequestMethod) != ""
+			if preflight {
+				header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
+				header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
+			}
+
+			allowCredentials := credentials[strings.ToLower(origin)]
+			allowed, anyOrigin := matchOrigin(cfg.AllowOrigins, origin)
+			switch {
+			case allowCredentials:
+				header.Set(echo.HeaderAccessControlAllowOrigin, origin)
+				header.Set(echo.HeaderAccessControlAllowCredentials, "true")
+			case anyOrigin:
+				header.Set(echo.HeaderAccessControlAllowOrigin, "*")
+			case allowed:
+				header.Set(echo.HeaderAccessControlAllowOrigin, origin)
+			case preflight:
+				return custErr.SetError(http.StatusForbidden, "Origin is not allowed")
+			default:
+				return next(c)
+			}
+
+			if !preflight {
+				if exposeHeaders != "" {
+					header.Set(echo.HeaderAccessControlExposeHeaders, exposeHeaders)
+				}
+				return next(c)
+			}
+
+			header.Set(echo.HeaderAccessControlAllowMethods, allowMethods)
+			if allowHeaders != "" {
+				header.Set(echo.HeaderAccessControlAllowHeaders, allowHeaders)
+			}
+			if cfg.MaxAge > 0 {
+				header.Set(echo.HeaderAccessControlMaxAge, maxAge)
+			}
+			return c.NoContent(http.StatusNoContent)
+		}
+	}
+}
+
+// matchOrigin reports whether origin is allowed, and whether only because any
+// origin is.
+func matchOrigin(patterns []string, origin string) (allowed, anyOrigin bool) {
+	origin = strings.ToLower(origin)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if pattern == "*" {
+			anyOrigin = true
+			continue
+		}
+
+		prefix, suffix, ok := strings.Cut(pattern, "*")
+		if !ok {
+			if pattern == origin {
+				return true, false
+			}
+			continue
+		}
+		if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true, false
+		}
+	}
+	return anyOrigin, anyOrigin
+}
+
+// bodyLimitMiddleware limits the request body without reading it, a read past
+// the limit fails with *http.MaxBytesError which is answered with 413.
+func bodyLimitMiddleware(cfg config.SecurityConfig) echo.MiddlewareFunc {
+	uploadRoutes := routeSet(cfg.UploadRoutes)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.Body == nil || req.Body == http.NoBody {
+				return next(c)
+			}
+
+			limit := int64(cfg.MaxBodySize)
+			if uploadRoutes[routeKey(c)] {
+				limit = int64(cfg.UploadMaxBodySize)
+			}
+			if req.ContentLength > limit {
+				return custErr.SetError(http.StatusRequestEntityTooLarge, "Request body is too large")
+			}
+
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+			return next(c)
+		}
+	}
+}
+
+// bodyReadError maps an error reading the request body to its response.
+func bodyReadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return custErr.SetError(http.StatusRequestEntityTooLarge, "Request body is too large")
+	}
+	return custErr.SetError(http.StatusBadRequest, "Request body cannot be read")
+}
+
+// routeSet indexes routes such as "POST /products" for routeKey.
+func routeSet(routes []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, route := range routes {
+		set[strings.Join(strings.Fields(route), " ")] = true
+	}
+	return set
+}
+
+func routeKey(c echo.Context) string {
+	return c.Request().Method + " " + c.Path()
+}
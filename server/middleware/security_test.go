@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/server/openapi"
+	"github.com/labstack/echo/v4"
+)
+
+func Test_securityHeadersMiddleware(t *testing.T) {
+	cfg := config.SecurityConfig{
+		HSTSMaxAge:                time.Hour,
+		HSTSIncludeSubdomains:     true,
+		ContentSecurityPolicy:     "default-src 'none'",
+		DocsContentSecurityPolicy: "default-src 'self'",
+		ReferrerPolicy:            "no-referrer",
+		FrameOptions:              "DENY",
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		headers  map[string]string
+		wantCSP  string
+		wantHSTS string
+	}{
+		{name: "api route over http", path: "/products", wantCSP: "default-src 'none'"},
+		{name: "html route", path: "/docs", wantCSP: "default-src 'self'"},
+		{
+			name:     "https behind a proxy",
+			path:     "/products",
+			headers:  map[string]string{echo.HeaderXForwardedProto: "https"},
+			wantCSP:  "default-src 'none'",
+			wantHSTS: "max-age=3600; includeSubDomains",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			docs := openapi.NewBuilder(openapi.Info{})
+			e.Use(securityHeadersMiddleware(cfg, docs))
+			docs.Add(e.GET("/products", func(c echo.Context) error {
+				return c.NoContent(http.StatusNoContent)
+			}), openapi.Spec{Response: ""})
+			docs.Add(e.GET("/docs", func(c echo.Context) error {
+				return c.HTML(http.StatusOK, "<html></html>")
+			}), openapi.Spec{Response: "", ContentType: echo.MIMETextHTML})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			want := map[string]string{
+				echo.HeaderContentSecurityPolicy:   tt.wantCSP,
+				echo.HeaderStrictTransportSecurity: tt.wantHSTS,
+				echo.HeaderXContentTypeOptions:     "nosniff",
+				echo.HeaderXFrameOptions:           "DENY",
+				echo.HeaderReferrerPolicy:          "no-referrer",
+			}
+			for key, value := range want {
+				if got := rec.Header().Get(key); got != value {
+					t.Errorf("securityHeadersMiddleware() %s = %q, want %q", key, got, value)
+				}
+			}
+		})
+	}
+}
+
+func Test_corsMiddleware(t *testing.T) {
+	cfg := config.CORSConfig{
+		AllowOrigins:      []string{"https://app.example.com", "https://*.example.org"},
+		CredentialOrigins: []string{"https://admin.example.com"},
+		AllowMethods:      []string{http.MethodGet, http.MethodPost},
+		AllowHeaders:      []string{"Content-Type"},
+		ExposeHeaders:     []string{"X-Request-ID"},
+		MaxAge:            time.Minute,
+	}
+
+	tests := []struct {
+		name            string
+		cfg             config.CORSConfig
+		method          string
+		origin          string
+		preflight       bool
+		wantStatus      int
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{name: "allowed origin", cfg: cfg, method: http.MethodGet, origin: "https://app.example.com", wantStatus: http.StatusNoContent, wantOrigin: "https://app.example.com"},
+		{name: "allowed subdomain", cfg: cfg, method: http.MethodGet, origin: "https://shop.example.org", wantStatus: http.StatusNoContent, wantOrigin: "https://shop.example.org"},
+		{name: "credential origin", cfg: cfg, method: http.MethodGet, origin: "https://admin.example.com", wantStatus: http.StatusNoContent, wantOrigin: "https://admin.example.com", wantCredentials: "true"},
+		{name: "unknown origin", cfg: cfg, method: http.MethodGet, origin: "https://evil.example.net", wantStatus: http.StatusNoContent},
+		{name: "preflight", cfg: cfg, method: http.MethodOptions, origin: "https://app.example.com", preflight: true, wantStatus: http.StatusNoContent, wantOrigin: "https://app.example.com"},
+		{name: "preflight of unknown origin", cfg: cfg, method: http.MethodOptions, origin: "https://evil.example.net", preflight: true, wantStatus: http.StatusForbidden},
+		{
+			name:       "any origin without credentials",
+			cfg:        config.CORSConfig{AllowOrigins: []string{"*"}, CredentialOrigins: []string{"https://admin.example.com"}},
+			method:     http.MethodGet,
+			origin:     "https://evil.example.net",
+			wantStatus: http.StatusNoContent,
+			wantOrigin: "*",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = errorHandler
+			e.Use(corsMiddleware(tt.cfg))
+			e.GET("/products", func(c echo.Context) error {
+				return c.NoContent(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(tt.method, "/products", nil)
+			req.Header.Set(echo.HeaderOrigin, tt.origin)
+			if tt.preflight {
+				req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("corsMiddleware() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get(echo.HeaderAccessControlAllowOrigin); got != tt.wantOrigin {
+				t.Errorf("corsMiddleware() allow origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := rec.Header().Get(echo.HeaderAccessControlAllowCredentials); got != tt.wantCredentials {
+				t.Errorf("corsMiddleware() allow credentials = %q, want %q", got, tt.wantCredentials)
+			}
+			if tt.preflight && tt.wantOrigin != "" && rec.Header().Get(echo.HeaderAccessControlAllowMethods) != "GET,POST" {
+				t.Errorf("corsMiddleware() allow methods = %q, want GET,POST", rec.Header().Get(echo.HeaderAccessControlAllowMethods))
+			}
+		})
+	}
+}
+
+func Test_bodyLimitMiddleware(t *testing.T) {
+	cfg := config.SecurityConfig{MaxBodySize: 10, UploadMaxBodySize: 20, UploadRoutes: []string{"POST /import"}}
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		chunked    bool
+		wantStatus int
+	}{
+		{name: "within the limit", path: "/products", body: "0123456789", wantStatus: http.StatusOK},
+		{name: "content length over the limit", path: "/products", body: "0123456789a", wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "chunked body over the limit", path: "/products", body: "0123456789a", chunked: true, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "upload route", path: "/import", body: "0123456789abcdefghij", wantStatus: http.StatusOK},
+		{name: "upload route over its limit", path: "/import", body: "0123456789abcdefghijk", wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = errorHandler
+			e.Use(bodyLimitMiddleware(cfg))
+			echoBody := func(c echo.Context) error {
+				body, err := io.ReadAll(c.Request().Body)
+				if err != nil {
+					return err
+				}
+				return c.String(http.StatusOK, string(body))
+			}
+			e.POST("/products", echoBody)
+			e.POST("/import", echoBody)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("bodyLimitMiddleware() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != tt.body {
+				t.Errorf("bodyLimitMiddleware() body = %q, want %q", rec.Body.String(), tt.body)
+			}
+		})
+	}
+}
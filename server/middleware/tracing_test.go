@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fadilahonespot/library/logres"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func Test_tracingMiddleware(t *testing.T) {
+	logger.NewLogger(config.LoggerConfig{})
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	tests := []struct {
+		name        string
+		traceparent string
+		wantTraceID string
+	}{
+		{
+			name:        "continue incoming trace",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name: "start new trace",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var threadID, traceID string
+			e := echo.New()
+			e.Use(tracingMiddleware())
+			e.Use(setLoggerMiddleware(7690, defaultRequestIDConfig, testRedactor, logger.Default()))
+			e.GET("/products", func(c echo.Context) error {
+				ctx := c.Request().Context()
+				threadID = logres.GetCtxLogger(ctx).ThreadID
+				traceID = trace.SpanContextFromContext(ctx).TraceID().String()
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/products", nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			e.ServeHTTP(httptest.NewRecorder(), req)
+
+			if tt.wantTraceID != "" && traceID != tt.wantTraceID {
+				t.Errorf("tracingMiddleware() trace id = %v, want %v", traceID, tt.wantTraceID)
+			}
+			if threadID != traceID {
+				t.Errorf("setLoggerMiddleware() thread id = %v, want trace id %v", threadID, traceID)
+			}
+		})
+	}
+}
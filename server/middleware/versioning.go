@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	custErr "github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+)
+
+// versionMediaType matches media types such as application/vnd.simple-api.v2+json.
+var versionMediaType = regexp.MustCompile(`^application/vnd\.simple-api\.(v[0-9]+)\+json$`)
+
+// VersionRouting serves unversioned paths of resources, such as /products,
+// from the version asked for in the Accept header, or else the default
+// version. It must be registered with Echo.Pre.
+func VersionRouting(cfg config.VersioningConfig, versions, resources []string) echo.MiddlewareFunc {
+	known := make(map[string]bool)
+	for _, version := range versions {
+		known[version] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if !isResourcePath(req.URL.Path, resources) {
+				return next(c)
+			}
+
+			c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+			version, asked := acceptedVersion(req.Header.Get(echo.HeaderAccept), known)
+			if asked && version == "" {
+				return custErr.SetError(http.StatusNotAcceptable, "API version is not supported")
+			}
+			if version == "" {
+				version = cfg.Default
+			}
+
+			req.URL.Path = "/" + version + req.URL.Path
+			if req.URL.RawPath != "" {
+				req.URL.RawPath = "/" + version + req.URL.RawPath
+			}
+			return next(c)
+		}
+	}
+}
+
+// APIVersion marks the responses of version as deprecated when configured
+// so, and counts the requests still made to it.
+func APIVersion(cfg config.VersioningConfig, version string) echo.MiddlewareFunc {
+	deprecatedAt, deprecated := cfg.DeprecatedAt(version)
+	sunsetAt, sunset := cfg.SunsetAt(version)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !deprecated && !sunset {
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			if deprecated {
+				header.Set(HeaderDeprecation, fmt.Sprintf("@%d", deprecatedAt.Unix()))
+			}
+			if sunset {
+				header.Set(HeaderSunset, sunsetAt.UTC().Format(http.TimeFormat))
+			}
+			if cfg.DeprecationLink != "" {
+				header.Add(HeaderLink, fmt.Sprintf(`<%s>; rel="deprecation"`, cfg.DeprecationLink))
+			}
+
+			metrics.DeprecatedRequestsTotal.WithLabelValues(version, c.Request().Method, c.Path()).Inc()
+			return next(c)
+		}
+	}
+}
+
+func isResourcePath(path string, resources []string) bool {
+	for _, resource := range resources {
+		prefix := "/" + resource
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedVersion returns the first known version among the versioned media
+// types of accept, and whether accept asked for any version at all.
+func acceptedVersion(accept string, known map[string]bool) (version string, asked bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		match := versionMediaType.FindStringSubmatch(mediaType)
+		if match == nil {
+			continue
+		}
+		asked = true
+		if known[match[1]] {
+			return match[1], true
+		}
+	}
+	return "", asked
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/labstack/echo/v4"
+)
+
+func TestVersionRouting(t *testing.T) {
+	cfg := config.VersioningConfig{
+		Default:         "v1",
+		Deprecated:      []string{"v1=2026-01-01"},
+		Sunset:          []string{"v1=2027-06-30"},
+		DeprecationLink: "https://example.com/migrate-to-v2",
+	}
+
+	tests := []struct {
+		name            string
+		path            string
+		accept          string
+		wantStatus      int
+		wantBody        string
+		wantDeprecation string
+		wantSunset      string
+	}{
+		{
+			name:            "unversioned path uses the default version",
+			path:            "/products",
+			wantStatus:      http.StatusOK,
+			wantBody:        "v1",
+			wantDeprecation: "@1767225600",
+			wantSunset:      "Wed, 30 Jun 2027 00:00:00 GMT",
+		},
+		{
+			name:       "version from the Accept header",
+			path:       "/products/42",
+			accept:     "application/vnd.simple-api.v2+json",
+			wantStatus: http.StatusOK,
+			wantBody:   "v2",
+		},
+		{
+			name:       "first known version of the Accept header",
+			path:       "/products",
+			accept:     "application/vnd.simple-api.v3+json, application/vnd.simple-api.v2+json;q=0.9",
+			wantStatus: http.StatusOK,
+			wantBody:   "v2",
+		},
+		{
+			name:       "unknown version in the Accept header",
+			path:       "/products",
+			accept:     "application/vnd.simple-api.v3+json",
+			wantStatus: http.StatusNotAcceptable,
+		},
+		{
+			name:       "path version wins over the Accept header",
+			path:       "/v2/products",
+			accept:     "application/vnd.simple-api.v1+json",
+			wantStatus: http.StatusOK,
+			wantBody:   "v2",
+		},
+		{
+			name:       "unversioned resource",
+			path:       "/healthz",
+			accept:     "application/vnd.simple-api.v2+json",
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = errorHandler
+			e.Pre(VersionRouting(cfg, []string{"v1", "v2"}, []string{"products"}))
+			for _, version := range []string{"v1", "v2"} {
+				version := version
+				g := e.Group("/"+version, APIVersion(cfg, version))
+				served := func(c echo.Context) error {
+					return c.String(http.StatusOK, version)
+				}
+				g.GET("/products", served)
+				g.GET("/products/:productId", served)
+			}
+			e.GET("/healthz", func(c echo.Context) error {
+				return c.String(http.StatusOK, "ok")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("VersionRouting() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if body, _ := io.ReadAll(rec.Body); string(body) != tt.wantBody {
+				t.Errorf("VersionRouting() served %q, want %q", body, tt.wantBody)
+			}
+			if got := rec.Header().Get(HeaderDeprecation); got != tt.wantDeprecation {
+				t.Errorf("APIVersion() %s = %q, want %q", HeaderDeprecation, got, tt.wantDeprecation)
+			}
+			if got := rec.Header().Get(HeaderSunset); got != tt.wantSunset {
+				t.Errorf("APIVersion() %s = %q, want %q", HeaderSunset, got, tt.wantSunset)
+			}
+			if got, want := rec.Header().Get(HeaderLink) != "", tt.wantDeprecation != ""; got != want {
+				t.Errorf("APIVersion() sent %s = %v, want %v", HeaderLink, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fadilahonespot/library/response"
+	"github.com/labstack/echo/v4"
+)
+
+type Envelope int
+
+const (
+	// EnvelopeNone documents Response as the raw body.
+	EnvelopeNone Envelope = iota
+	// EnvelopeData wraps Response in the library response.Response.
+	EnvelopeData
+	// EnvelopePagination wraps Response in the paginated response.
+	EnvelopePagination
+)
+
+var pathParamPattern = regexp.MustCompile(`:([^/]+)`)
+
+type Spec struct {
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	Parameters  []Parameter
+	Request     interface{}
+	Response    interface{}
+	Envelope    Envelope
+	// Fieldset is the resource whose fields ?fields= selects, such as the
+	// product of a list.
+	Fieldset interface{}
+	// Alternatives document further success bodies, such as the batch
+	// result a list returns instead of a page for some parameters.
+	Alternatives []Alternative
+	// ContentType of the success response, defaults to application/json.
+	ContentType string
+	// Consumes and Produces list further media types of the request and of
+	// the responses, rendered from the same schemas as JSON.
+	Consumes []string
+	Produces []string
+	// Status of the success response, defaults to 200. A 101 response is
+	// documented without a body.
+	Status int
+	// Errors lists the documented error statuses besides 500.
+	Errors []int
+	// Responses documents further statuses whose body is not an error.
+	Responses  map[int]interface{}
+	Deprecated bool
+}
+
+// Alternative is a success body documented with anyOf next to the Response
+// of a Spec.
+type Alternative struct {
+	Response interface{}
+	Envelope Envelope
+}
+
+type route struct {
+	method string
+	path   string
+	name   string
+	spec   Spec
+}
+
+type Builder struct {
+	info   Info
+	mu     sync.Mutex
+	routes []route
+	doc    *Document
+}
+
+func NewBuilder(info Info) *Builder {
+	return &Builder{info: info}
+}
+
+func (b *Builder) Add(r *echo.Route, spec Spec) *echo.Route {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.routes = append(b.routes, route{method: r.Method, path: r.Path, name: r.Name, spec: spec})
+	b.doc = nil
+	return r
+}
+
+func PathParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true, Schema: schema}
+}
+
+func QueryParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+// Path converts an echo route path such as /products/:productId to its
+// OpenAPI form /products/{productId}.
+func Path(echoPath string) string {
+	return pathParamPattern.ReplaceAllString(echoPath, "{$1}")
+}
+
+func (b *Builder) Document() *Document {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.doc != nil {
+		return b.doc
+	}
+
+	generator := newSchemaGenerator()
+	generator.components["ErrorResponse"] = errorResponseSchema()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    b.info,
+		Paths:   make(map[string]*PathItem),
+	}
+
+	for _, r := range b.routes {
+		path := Path(r.path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+
+		operation := buildOperation(generator, r)
+		switch r.method {
+		case http.MethodGet:
+			item.Get = operation
+		case http.MethodPost:
+			item.Post = operation
+		case http.MethodPut:
+			item.Put = operation
+		case http.MethodPatch:
+			item.Patch = operation
+		case http.MethodDelete:
+			item.Delete = operation
+		}
+	}
+
+	doc.Components.Schemas = generator.components
+	b.doc = doc
+	return doc
+}
+
+func (b *Builder) Handler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, b.Document())
+	}
+}
+
+func buildOperation(generator *schemaGenerator, r route) *Operation {
+	spec := r.spec
+	operation := &Operation{
+		OperationID: spec.OperationID,
+		Summary:     spec.Summary,
+		Description: spec.Description,
+		Tags:        spec.Tags,
+		Deprecated:  spec.Deprecated,
+		Responses:   make(map[string]Response),
+	}
+	if operation.OperationID == "" {
+		operation.OperationID = OperationID(r.name)
+	}
+
+	documented := make(map[string]bool)
+	for _, param := range spec.Parameters {
+		documented[param.In+":"+param.Name] = true
+		operation.Parameters = append(operation.Parameters, param)
+	}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(r.path, -1) {
+		if !documented["path:"+match[1]] {
+			operation.Parameters = append(operation.Parameters, PathParam(match[1], "", &Schema{Type: "string"}))
+		}
+	}
+
+	if spec.Fieldset != nil {
+		operation.Fieldset = strings.TrimPrefix(generator.ResponseSchemaOf(spec.Fieldset).Ref, componentRefPrefix)
+	}
+
+	if spec.Request != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  content(generator.RequestSchemaOf(spec.Request), echo.MIMEApplicationJSON, spec.Consumes),
+		}
+	}
+
+	contentType := spec.ContentType
+	if contentType == "" {
+		contentType = echo.MIMEApplicationJSON
+	}
+	status := spec.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	if status != http.StatusSwitchingProtocols {
+		success.Content = content(successSchema(generator, spec), contentType, spec.Produces)
+	}
+	operation.Responses[strconv.Itoa(status)] = success
+
+	for status, body := range spec.Responses {
+		data := generator.ResponseSchemaOf(body)
+		if data == nil {
+			data = &Schema{Type: "null"}
+		}
+		operation.Responses[strconv.Itoa(status)] = Response{
+			Description: http.StatusText(status),
+			Content:     content(data, contentType, spec.Produces),
+		}
+	}
+
+	errorStatuses := append([]int{http.StatusInternalServerError}, spec.Errors...)
+	sort.Ints(errorStatuses)
+	for _, status := range errorStatuses {
+		operation.Responses[strconv.Itoa(status)] = Response{
+			Description: http.StatusText(status),
+			Content:     content(&Schema{Ref: "#/components/schemas/ErrorResponse"}, echo.MIMEApplicationJSON, spec.Produces),
+		}
+	}
+
+	return operation
+}
+
+func content(schema *Schema, contentType string, others []string) map[string]MediaType {
+	content := map[string]MediaType{contentType: {Schema: schema}}
+	for _, other := range others {
+		content[other] = MediaType{Schema: schema}
+	}
+	return content
+}
+
+func successSchema(generator *schemaGenerator, spec Spec) *Schema {
+	schema := bodySchema(generator, spec.Response, spec.Envelope)
+	if len(spec.Alternatives) == 0 {
+		return schema
+	}
+
+	alternatives := &Schema{AnyOf: []*Schema{schema}}
+	for _, alternative := range spec.Alternatives {
+		alternatives.AnyOf = append(alternatives.AnyOf, bodySchema(generator, alternative.Response, alternative.Envelope))
+	}
+	return alternatives
+}
+
+func bodySchema(generator *schemaGenerator, body interface{}, envelope Envelope) *Schema {
+	data := generator.ResponseSchemaOf(body)
+	if data == nil {
+		data = &Schema{Type: "null"}
+	}
+
+	switch envelope {
+	case EnvelopeData:
+		return envelopeSchema(data)
+	case EnvelopePagination:
+		schema := envelopeSchema(data)
+		schema.Properties["pagination"] = generator.ResponseSchemaOf(response.ItemPages{})
+		schema.Required = append(schema.Required, "pagination")
+		return schema
+	}
+	return data
+}
+
+func envelopeSchema(data *Schema) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":    {Type: "integer"},
+			"message": {Type: "string"},
+			"data":    data,
+		},
+		Required: []string{"code", "message", "data"},
+	}
+}
+
+func errorResponseSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":      {Type: "integer"},
+			"message":   {Type: "string"},
+			"data":      {Type: "object"},
+			"requestId": {Type: "string"},
+		},
+		Required: []string{"code", "message"},
+	}
+}
+
+// OperationID derives an operation ID from an echo route name such as
+// github.com/org/repo/server/handler.(*ProductHandler).AddProduct-fm.
+func OperationID(name string) string {
+	name = strings.TrimSuffix(name, "-fm")
+	if index := strings.LastIndex(name, "."); index >= 0 {
+		name = name[index+1:]
+	}
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
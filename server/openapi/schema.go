@@ -0,0 +1,237 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	uuidType      = reflect.TypeOf(uuid.UUID{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+)
+
+type schemaGenerator struct {
+	components map[string]*Schema
+	// request marks fields optional unless their validate tag requires them.
+	// Response fields are required unless they are nillable or omitempty.
+	request bool
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{components: make(map[string]*Schema)}
+}
+
+// RequestSchemaOf and ResponseSchemaOf return the schema of v. Named structs
+// are stored as components and referenced.
+func (g *schemaGenerator) RequestSchemaOf(v interface{}) *Schema {
+	g.request = true
+	return g.schemaOf(v)
+}
+
+func (g *schemaGenerator) ResponseSchemaOf(v interface{}) *Schema {
+	g.request = false
+	return g.schemaOf(v)
+}
+
+func (g *schemaGenerator) schemaOf(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+	return g.schemaOfType(reflect.TypeOf(v))
+}
+
+func (g *schemaGenerator) schemaOfType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case deletedAtType:
+		return &Schema{Type: []string{"string", "null"}, Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		schema := &Schema{Type: "array", Items: g.schemaOfType(t.Elem())}
+		if !g.request && t.Kind() == reflect.Slice {
+			// A nil slice is encoded as null.
+			schema.Type = []string{"array", "null"}
+		}
+		return schema
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+
+		name := t.Name()
+		if _, ok := g.components[name]; !ok {
+			g.components[name] = &Schema{}
+			*g.components[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	return &Schema{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(schema, t)
+	return schema
+}
+
+func (g *schemaGenerator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(schema, embedded)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitEmpty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+
+		property := g.schemaOfType(field.Type)
+		required := applyValidation(property, field)
+		if !g.request && !omitEmpty && field.Type.Kind() == reflect.Ptr {
+			// A nil pointer is encoded as null.
+			property = &Schema{AnyOf: []*Schema{property, {Type: "null"}}}
+		}
+		schema.Properties[name] = property
+
+		if required || (!g.request && !omitEmpty && isAlwaysPresent(field.Type)) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// applyValidation maps go-playground/validator tags onto schema constraints
+// and reports whether the field is required.
+func applyValidation(schema *Schema, field reflect.StructField) (required bool) {
+	tag := field.Tag.Get("validate")
+	if tag == "" || schema.Ref != "" {
+		return strings.Contains(tag, "required")
+	}
+
+	isString := schema.Type == "string"
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+			if isString && schema.MinLength == nil {
+				schema.MinLength = intPtr(1)
+			}
+		case "min", "gte":
+			if isString {
+				schema.MinLength = intPtr(atoi(param))
+			} else {
+				schema.Minimum = floatPtr(atof(param))
+			}
+		case "max", "lte":
+			if isString {
+				schema.MaxLength = intPtr(atoi(param))
+			} else {
+				schema.Maximum = floatPtr(atof(param))
+			}
+		case "len":
+			if isString {
+				schema.MinLength = intPtr(atoi(param))
+				schema.MaxLength = intPtr(atoi(param))
+			}
+		case "gt":
+			schema.ExclusiveMinimum = floatPtr(atof(param))
+		case "lt":
+			schema.ExclusiveMaximum = floatPtr(atof(param))
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, value)
+			}
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "email":
+			schema.Format = "email"
+		}
+	}
+	return
+}
+
+func isAlwaysPresent(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return false
+	}
+	return true
+}
+
+func jsonName(field reflect.StructField) (name string, omitEmpty bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func atoi(s string) int {
+	v, _ := strconv.Atoi(s)
+	return v
+}
+
+func atof(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
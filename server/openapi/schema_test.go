@@ -0,0 +1,79 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "static", path: "/products", want: "/products"},
+		{name: "param", path: "/products/:productId", want: "/products/{productId}"},
+		{name: "nested params", path: "/a/:b/c/:d", want: "/a/{b}/c/{d}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Path(tt.path); got != tt.want {
+				t.Errorf("Path() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_schemaGenerator_RequestSchemaOf(t *testing.T) {
+	type request struct {
+		Name   string   `json:"name" validate:"required,max=20"`
+		Kind   string   `json:"kind,omitempty" validate:"oneof=a b"`
+		Rating float64  `json:"rating" validate:"gte=0,lte=5"`
+		Tags   []string `json:"tags"`
+		Secret string   `json:"-"`
+	}
+
+	generator := newSchemaGenerator()
+	ref := generator.RequestSchemaOf(request{})
+	if ref.Ref != "#/components/schemas/request" {
+		t.Fatalf("RequestSchemaOf() ref = %v", ref.Ref)
+	}
+
+	schema := generator.components["request"]
+	if !reflect.DeepEqual(schema.Required, []string{"name"}) {
+		t.Errorf("required = %v, want [name]", schema.Required)
+	}
+	if _, ok := schema.Properties["Secret"]; ok {
+		t.Errorf("ignored field is documented")
+	}
+	if got := schema.Properties["name"]; *got.MinLength != 1 || *got.MaxLength != 20 {
+		t.Errorf("name length = %v..%v, want 1..20", *got.MinLength, *got.MaxLength)
+	}
+	if got := schema.Properties["kind"].Enum; !reflect.DeepEqual(got, []interface{}{"a", "b"}) {
+		t.Errorf("kind enum = %v", got)
+	}
+	if got := schema.Properties["rating"]; *got.Minimum != 0 || *got.Maximum != 5 {
+		t.Errorf("rating range = %v..%v, want 0..5", *got.Minimum, *got.Maximum)
+	}
+	if got := schema.Properties["tags"]; got.Type != "array" || got.Items.Type != "string" {
+		t.Errorf("tags = %+v", got)
+	}
+}
+
+func Test_schemaGenerator_ResponseSchemaOf(t *testing.T) {
+	type response struct {
+		ID       string  `json:"id"`
+		Optional *string `json:"optional"`
+		Omitted  int     `json:"omitted,omitempty"`
+	}
+
+	generator := newSchemaGenerator()
+	generator.ResponseSchemaOf(response{})
+
+	if got := generator.components["response"].Required; !reflect.DeepEqual(got, []string{"id"}) {
+		t.Errorf("required = %v, want [id]", got)
+	}
+	if got := generator.components["response"].Properties["optional"].AnyOf; len(got) != 2 || got[1].Type != "null" {
+		t.Errorf("optional anyOf = %v, want string or null", got)
+	}
+}
@@ -0,0 +1,17 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+func UIHandler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.HTMLBlob(http.StatusOK, docsPage)
+	}
+}
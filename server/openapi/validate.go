@@ -0,0 +1,410 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fadilahonespot/simple-api/server/render"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const componentRefPrefix = "#/components/schemas/"
+
+// Violation describes a single mismatch between a message and the document.
+type Violation struct {
+	In      string `json:"in"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Field == "" {
+		return v.In + ": " + v.Message
+	}
+	return v.In + " " + v.Field + ": " + v.Message
+}
+
+// Operation returns the operation documented for method and the echo route
+// path, or nil when the route is undocumented.
+func (d *Document) Operation(method, echoPath string) *Operation {
+	item, ok := d.Paths[Path(echoPath)]
+	if !ok {
+		return nil
+	}
+
+	switch method {
+	case http.MethodGet:
+		return item.Get
+	case http.MethodPost:
+		return item.Post
+	case http.MethodPut:
+		return item.Put
+	case http.MethodPatch:
+		return item.Patch
+	case http.MethodDelete:
+		return item.Delete
+	}
+	return nil
+}
+
+// Produces reports whether the success response of o is of contentType.
+func (o *Operation) Produces(contentType string) bool {
+	if o == nil {
+		return false
+	}
+	_, ok := o.Responses[strconv.Itoa(http.StatusOK)].Content[contentType]
+	return ok
+}
+
+// ValidateRequest checks the path and query parameters and the JSON body of
+// c against operation. The request body is left readable for the handler.
+func (d *Document) ValidateRequest(operation *Operation, c echo.Context, body []byte) (violations []Violation) {
+	for _, param := range operation.Parameters {
+		var raw string
+		var present bool
+		switch param.In {
+		case "path":
+			raw = c.Param(param.Name)
+			present = raw != ""
+		case "query":
+			_, present = c.QueryParams()[param.Name]
+			raw = c.QueryParam(param.Name)
+		default:
+			continue
+		}
+		violations = append(violations, d.validateParameter(param, raw, present)...)
+	}
+
+	if operation.RequestBody == nil {
+		return
+	}
+
+	contentType := mediaType(c.Request().Header.Get(echo.HeaderContentType))
+	media, ok := operation.RequestBody.Content[contentType]
+	if contentType != "" && !ok && !render.IsJSON(contentType) {
+		return
+	}
+	if !ok {
+		media = operation.RequestBody.Content[echo.MIMEApplicationJSON]
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if operation.RequestBody.Required {
+			violations = append(violations, Violation{In: "body", Message: "request body is required"})
+		}
+		return
+	}
+	// Like responses, only JSON bodies are checked against the schema.
+	if contentType != "" && !render.IsJSON(contentType) {
+		return
+	}
+
+	value, err := decodeJSON(body)
+	if err != nil {
+		return append(violations, Violation{In: "body", Message: "request body is not valid JSON"})
+	}
+	return append(violations, d.ValidateValue(media.Schema, value, "body", "")...)
+}
+
+// ValidateResponse checks status and JSON body of a response against operation.
+func (d *Document) ValidateResponse(operation *Operation, status int, contentType string, body []byte) []Violation {
+	documented, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		documented, ok = operation.Responses["default"]
+	}
+	if !ok {
+		return []Violation{{In: "response", Message: fmt.Sprintf("status %d is not documented", status)}}
+	}
+
+	if len(documented.Content) == 0 {
+		return nil
+	}
+
+	contentType = mediaType(contentType)
+	media, ok := documented.Content[contentType]
+	if !ok {
+		return []Violation{{In: "response", Message: fmt.Sprintf("content type %q is not documented", contentType)}}
+	}
+	if !render.IsJSON(contentType) {
+		return nil
+	}
+
+	value, err := decodeJSON(body)
+	if err != nil {
+		return []Violation{{In: "response", Message: "response body is not valid JSON"}}
+	}
+	return d.ValidateValue(media.Schema, value, "response", "")
+}
+
+// ValidateSparseResponse is ValidateResponse for a response with only fields
+// selected, as asked for with ?fields=. The fieldset schema of operation
+// only requires those of its required fields selected, the schemas around
+// it are checked in full.
+func (d *Document) ValidateSparseResponse(operation *Operation, status int, contentType string, body []byte, fields []string) []Violation {
+	schema, ok := d.Components.Schemas[operation.Fieldset]
+	if !ok {
+		return d.ValidateResponse(operation, status, contentType, body)
+	}
+
+	sparse := *d
+	sparse.Components.Schemas = make(map[string]*Schema, len(d.Components.Schemas))
+	for name, schema := range d.Components.Schemas {
+		sparse.Components.Schemas[name] = schema
+	}
+	sparse.Components.Schemas[operation.Fieldset] = sparseSchema(schema, fields)
+	return sparse.ValidateResponse(operation, status, contentType, body)
+}
+
+func sparseSchema(schema *Schema, fields []string) *Schema {
+	sparse := *schema
+	sparse.Required = nil
+	for _, name := range schema.Required {
+		for _, field := range fields {
+			if name == field {
+				sparse.Required = append(sparse.Required, name)
+			}
+		}
+	}
+	return &sparse
+}
+
+func (d *Document) validateParameter(param Parameter, raw string, present bool) []Violation {
+	if !present {
+		if param.Required {
+			return []Violation{{In: param.In, Field: param.Name, Message: "is required"}}
+		}
+		return nil
+	}
+
+	schema := d.resolve(param.Schema)
+	var value interface{} = raw
+	switch {
+	case schema == nil:
+	case hasType(schema, "integer"):
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return []Violation{{In: param.In, Field: param.Name, Message: "must be an integer"}}
+		}
+		value = json.Number(raw)
+	case hasType(schema, "number"):
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return []Violation{{In: param.In, Field: param.Name, Message: "must be a number"}}
+		}
+		value = json.Number(raw)
+	case hasType(schema, "boolean"):
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return []Violation{{In: param.In, Field: param.Name, Message: "must be a boolean"}}
+		}
+		value = parsed
+	}
+
+	return d.ValidateValue(param.Schema, value, param.In, param.Name)
+}
+
+// ValidateValue checks a value decoded with json.Decoder.UseNumber against
+// schema. field is the dotted path of value used in the violations.
+func (d *Document) ValidateValue(schema *Schema, value interface{}, in, field string) (violations []Violation) {
+	schema = d.resolve(schema)
+	if schema == nil {
+		return nil
+	}
+
+	violation := func(format string, args ...interface{}) []Violation {
+		return append(violations, Violation{In: in, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// The violations of the first alternative are reported when none match.
+	for i, alternative := range schema.AnyOf {
+		found := d.ValidateValue(alternative, value, in, field)
+		if len(found) == 0 {
+			return nil
+		}
+		if i == 0 {
+			violations = found
+		}
+	}
+	if len(schema.AnyOf) > 0 {
+		return violations
+	}
+
+	if !matchesType(schema, value) {
+		return violation("must be of type %s", typeNames(schema))
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		return violation("must be one of %v", schema.Enum)
+	}
+
+	switch value := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(value)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			return violation("must be at least %d characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			return violation("must be at most %d characters", *schema.MaxLength)
+		}
+		if schema.Pattern != "" {
+			if pattern, err := regexp.Compile(schema.Pattern); err == nil && !pattern.MatchString(value) {
+				return violation("must match pattern %s", schema.Pattern)
+			}
+		}
+		if message := validateFormat(schema.Format, value); message != "" {
+			return violation(message)
+		}
+	case json.Number:
+		number, _ := value.Float64()
+		if schema.Minimum != nil && number < *schema.Minimum {
+			return violation("must be greater than or equal to %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && number > *schema.Maximum {
+			return violation("must be less than or equal to %v", *schema.Maximum)
+		}
+		if schema.ExclusiveMinimum != nil && number <= *schema.ExclusiveMinimum {
+			return violation("must be greater than %v", *schema.ExclusiveMinimum)
+		}
+		if schema.ExclusiveMaximum != nil && number >= *schema.ExclusiveMaximum {
+			return violation("must be less than %v", *schema.ExclusiveMaximum)
+		}
+	case []interface{}:
+		for i, item := range value {
+			violations = append(violations, d.ValidateValue(schema.Items, item, in, joinField(field, strconv.Itoa(i)))...)
+		}
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := value[name]; !ok {
+				violations = append(violations, Violation{In: in, Field: joinField(field, name), Message: "is required"})
+			}
+		}
+		for name, property := range schema.Properties {
+			if item, ok := value[name]; ok {
+				violations = append(violations, d.ValidateValue(property, item, in, joinField(field, name))...)
+			}
+		}
+	}
+
+	return violations
+}
+
+func (d *Document) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, componentRefPrefix)]
+	}
+	return schema
+}
+
+func validateFormat(format, value string) string {
+	switch format {
+	case "uuid":
+		if _, err := uuid.Parse(value); err != nil {
+			return "must be a valid UUID"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	}
+	return ""
+}
+
+func typeNames(schema *Schema) []string {
+	switch t := schema.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	}
+	return nil
+}
+
+func hasType(schema *Schema, name string) bool {
+	for _, t := range typeNames(schema) {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesType(schema *Schema, value interface{}) bool {
+	names := typeNames(schema)
+	if len(names) == 0 {
+		return true
+	}
+
+	for _, name := range names {
+		switch value := value.(type) {
+		case nil:
+			if name == "null" {
+				return true
+			}
+		case string:
+			if name == "string" {
+				return true
+			}
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+		case json.Number:
+			if name == "number" {
+				return true
+			}
+			if _, err := value.Int64(); err == nil && name == "integer" {
+				return true
+			}
+		case []interface{}:
+			if name == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if name == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, item := range enum {
+		if fmt.Sprint(item) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return parsed
+}
+
+func decodeJSON(body []byte) (value interface{}, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	err = decoder.Decode(&value)
+	return
+}
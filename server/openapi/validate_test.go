@@ -0,0 +1,180 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestDocument_ValidateResponse(t *testing.T) {
+	type owner struct {
+		Name string `json:"name"`
+	}
+	type item struct {
+		ID    string   `json:"id"`
+		Tags  []string `json:"tags"`
+		Count int      `json:"count"`
+		Owner *owner   `json:"owner"`
+	}
+
+	e := echo.New()
+	builder := NewBuilder(Info{Title: "test", Version: "1"})
+	builder.Add(e.GET("/items", func(c echo.Context) error { return nil }), Spec{
+		Response: []item{},
+		Envelope: EnvelopeData,
+		Alternatives: []Alternative{
+			{Response: owner{}, Envelope: EnvelopeData},
+		},
+		Errors: []int{http.StatusNotFound},
+	})
+	doc := builder.Document()
+	operation := doc.Operation(http.MethodGet, "/items")
+
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantCount   int
+	}{
+		{
+			name:        "valid response",
+			status:      http.StatusOK,
+			contentType: echo.MIMEApplicationJSONCharsetUTF8,
+			body:        `{"code":200,"message":"Success","data":[{"id":"a","tags":null,"count":1,"owner":{"name":"b"}}]}`,
+		},
+		{
+			name:        "null pointer",
+			status:      http.StatusOK,
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"code":200,"message":"Success","data":[{"id":"a","tags":null,"count":1,"owner":null}]}`,
+		},
+		{
+			name:        "invalid pointer",
+			status:      http.StatusOK,
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"code":200,"message":"Success","data":[{"id":"a","tags":null,"count":1,"owner":{}}]}`,
+			wantCount:   1,
+		},
+		{
+			name:        "alternative response",
+			status:      http.StatusOK,
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"code":200,"message":"Success","data":{"name":"b"}}`,
+		},
+		{
+			name:        "nil slice data",
+			status:      http.StatusOK,
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"code":200,"message":"Success","data":null}`,
+		},
+		{
+			name:        "documented error",
+			status:      http.StatusNotFound,
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"code":404,"message":"Not Found","data":{}}`,
+		},
+		{
+			name:        "wrong field type and missing field",
+			status:      http.StatusOK,
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"code":200,"message":"Success","data":[{"id":1,"tags":[],"owner":null}]}`,
+			wantCount:   2,
+		},
+		{
+			name:        "undocumented status",
+			status:      http.StatusTeapot,
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{}`,
+			wantCount:   1,
+		},
+		{
+			name:        "undocumented content type",
+			status:      http.StatusOK,
+			contentType: echo.MIMETextPlain,
+			body:        `ok`,
+			wantCount:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := doc.ValidateResponse(operation, tt.status, tt.contentType, []byte(tt.body))
+			if len(got) != tt.wantCount {
+				t.Errorf("Document.ValidateResponse() = %v, want %d violations", got, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestDocument_ValidateSparseResponse(t *testing.T) {
+	type item struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+		Count int    `json:"count"`
+	}
+	type result struct {
+		ID      string `json:"id"`
+		Found   bool   `json:"found"`
+		Product *item  `json:"product"`
+	}
+
+	e := echo.New()
+	builder := NewBuilder(Info{Title: "test", Version: "1"})
+	builder.Add(e.GET("/items", func(c echo.Context) error { return nil }), Spec{
+		Response: []result{},
+		Envelope: EnvelopeData,
+		Fieldset: item{},
+	})
+	doc := builder.Document()
+	operation := doc.Operation(http.MethodGet, "/items")
+
+	tests := []struct {
+		name      string
+		fields    []string
+		body      string
+		wantCount int
+	}{
+		{
+			name:   "selected fields",
+			fields: []string{"title"},
+			body:   `{"code":200,"message":"Success","data":[{"id":"a","found":true,"product":{"title":"b"}}]}`,
+		},
+		{
+			name:      "missing selected field",
+			fields:    []string{"title", "count"},
+			body:      `{"code":200,"message":"Success","data":[{"id":"a","found":true,"product":{"title":"b"}}]}`,
+			wantCount: 1,
+		},
+		{
+			name:      "wrong selected field type",
+			fields:    []string{"title"},
+			body:      `{"code":200,"message":"Success","data":[{"id":"a","found":true,"product":{"title":1}}]}`,
+			wantCount: 1,
+		},
+		{
+			name:      "missing field of the result with a selected field of its own",
+			fields:    []string{"id"},
+			body:      `{"code":200,"message":"Success","data":[{"id":"a","product":{"id":"a"}}]}`,
+			wantCount: 1,
+		},
+		{
+			name:      "missing field of the result",
+			fields:    []string{"title"},
+			body:      `{"code":200,"message":"Success","data":[{"id":"a","product":{"title":"b"}}]}`,
+			wantCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := doc.ValidateSparseResponse(operation, http.StatusOK, echo.MIMEApplicationJSON, []byte(tt.body), tt.fields)
+			if len(got) != tt.wantCount {
+				t.Errorf("Document.ValidateSparseResponse() = %v, want %d violations", got, tt.wantCount)
+			}
+		})
+	}
+
+	if got := doc.ValidateResponse(operation, http.StatusOK, echo.MIMEApplicationJSON, []byte(tests[0].body)); len(got) != 2 {
+		t.Errorf("Document.ValidateResponse() = %v, want the sparse fields to stay required", got)
+	}
+}
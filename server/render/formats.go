@@ -0,0 +1,281 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	httpv1 "github.com/fadilahonespot/simple-api/proto/http/v1"
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// paginationHeaders carry the pagination of CSV responses, which only hold
+// the rows of data.
+var paginationHeaders = map[string]string{
+	"page":      "X-Pagination-Page",
+	"limit":     "X-Pagination-Limit",
+	"totalData": "X-Pagination-Total-Data",
+	"totalPage": "X-Pagination-Total-Page",
+}
+
+func encodeJSON(c echo.Context, status int, resp interface{}) error {
+	return c.JSON(status, resp)
+}
+
+func decodeJSON(c echo.Context, v interface{}) error {
+	return c.Echo().JSONSerializer.Deserialize(c, v)
+}
+
+// encodeXML writes the envelope as a response element, list items as item
+// elements and null values as empty elements.
+func encodeXML(c echo.Context, status int, resp interface{}) error {
+	tree, err := toTree(resp)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	err = writeXML(encoder, "response", tree)
+	if err != nil {
+		return err
+	}
+	err = encoder.Flush()
+	if err != nil {
+		return err
+	}
+	return c.Blob(status, echo.MIMEApplicationXMLCharsetUTF8, buf.Bytes())
+}
+
+func writeXML(encoder *xml.Encoder, name string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case object:
+		for _, m := range value {
+			err = writeXML(encoder, m.key, m.value)
+			if err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			err = writeXML(encoder, "item", item)
+			if err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		err = encoder.EncodeToken(xml.CharData(fmt.Sprint(value)))
+		if err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+func decodeXML(c echo.Context, v interface{}) error {
+	return xml.NewDecoder(c.Request().Body).Decode(v)
+}
+
+func encodeMessagePack(c echo.Context, status int, resp interface{}) error {
+	tree, err := toTree(resp)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	err = writeMessagePack(msgpack.NewEncoder(&buf), tree)
+	if err != nil {
+		return err
+	}
+	return c.Blob(status, MIMEMessagePack, buf.Bytes())
+}
+
+func writeMessagePack(encoder *msgpack.Encoder, value interface{}) error {
+	switch value := value.(type) {
+	case object:
+		err := encoder.EncodeMapLen(len(value))
+		if err != nil {
+			return err
+		}
+		for _, m := range value {
+			err = encoder.EncodeString(m.key)
+			if err != nil {
+				return err
+			}
+			err = writeMessagePack(encoder, m.value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		err := encoder.EncodeArrayLen(len(value))
+		if err != nil {
+			return err
+		}
+		for _, item := range value {
+			err = writeMessagePack(encoder, item)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return encoder.EncodeInt(i)
+		}
+		f, err := value.Float64()
+		if err != nil {
+			return err
+		}
+		return encoder.EncodeFloat64(f)
+	}
+	return encoder.Encode(value)
+}
+
+func decodeMessagePack(c echo.Context, v interface{}) error {
+	decoder := msgpack.NewDecoder(c.Request().Body)
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(v)
+}
+
+// encodeProtobuf writes the envelope as an httpv1.Response, data keeps the
+// shape of the JSON response as a google.protobuf.Value.
+func encodeProtobuf(c echo.Context, status int, resp interface{}) error {
+	tree, err := toTree(resp)
+	if err != nil {
+		return err
+	}
+
+	envelope, _ := tree.(object)
+	message := &httpv1.Response{Code: int32(integer(envelope.get("code")))}
+	message.Message, _ = envelope.get("message").(string)
+	message.RequestId, _ = envelope.get("requestId").(string)
+	message.Data, err = structpb.NewValue(plain(envelope.get("data")))
+	if err != nil {
+		return err
+	}
+	if pagination, ok := envelope.get("pagination").(object); ok {
+		message.Pagination = &httpv1.Pagination{
+			Page:      integer(pagination.get("page")),
+			Limit:     integer(pagination.get("limit")),
+			TotalData: integer(pagination.get("totalData")),
+			TotalPage: integer(pagination.get("totalPage")),
+		}
+	}
+
+	body, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return c.Blob(status, MIMEProtobuf, body)
+}
+
+// decodeProtobuf reads a google.protobuf.Struct with the fields of the JSON
+// request.
+func decodeProtobuf(c echo.Context, v interface{}) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	var request structpb.Struct
+	err = proto.Unmarshal(body, &request)
+	if err != nil {
+		return err
+	}
+	content, err := request.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
+}
+
+// encodeCSV writes the objects of a list response as rows, with their
+// fields as columns. Other responses, such as errors, are written as JSON.
+func encodeCSV(c echo.Context, status int, resp interface{}) error {
+	tree, err := toTree(resp)
+	if err != nil {
+		return err
+	}
+
+	envelope, _ := tree.(object)
+	items, ok := envelope.get("data").([]interface{})
+	if !ok {
+		return encodeJSON(c, status, resp)
+	}
+	rows := make([]object, 0, len(items))
+	for _, item := range items {
+		row, ok := item.(object)
+		if !ok {
+			return encodeJSON(c, status, resp)
+		}
+		rows = append(rows, row)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if len(rows) > 0 {
+		columns := make([]string, 0, len(rows[0]))
+		for _, m := range rows[0] {
+			columns = append(columns, m.key)
+		}
+		writer.Write(columns)
+
+		for _, row := range rows {
+			record := make([]string, 0, len(columns))
+			for _, column := range columns {
+				record = append(record, csvCell(row.get(column)))
+			}
+			writer.Write(record)
+		}
+	}
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return err
+	}
+
+	if pagination, ok := envelope.get("pagination").(object); ok {
+		header := c.Response().Header()
+		for _, m := range pagination {
+			if name, ok := paginationHeaders[m.key]; ok {
+				header.Set(name, fmt.Sprint(m.value))
+			}
+		}
+	}
+	return c.Blob(status, MIMETextCSV+"; charset=utf-8", buf.Bytes())
+}
+
+func csvCell(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case object, []interface{}:
+		content, _ := json.Marshal(value)
+		return string(content)
+	}
+	return fmt.Sprint(value)
+}
+
+func integer(value interface{}) int64 {
+	number, _ := value.(json.Number)
+	i, _ := number.Int64()
+	return i
+}
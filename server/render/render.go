@@ -0,0 +1,227 @@
+package render
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	custErr "github.com/fadilahonespot/library/errors"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	MIMEMessagePack = "application/msgpack"
+	MIMEProtobuf    = "application/x-protobuf"
+	MIMETextCSV     = "text/csv"
+
+	formatKey = "render-format"
+)
+
+// Format is a media type responses are rendered in and, unless it only
+// renders lists, request bodies are decoded from.
+type Format struct {
+	// MediaType is sent as the Content-Type, Aliases are accepted as well.
+	MediaType string
+	Aliases   []string
+
+	encode func(c echo.Context, status int, resp interface{}) error
+	decode func(c echo.Context, v interface{}) error
+}
+
+var (
+	JSON = &Format{
+		MediaType: echo.MIMEApplicationJSON,
+		encode:    encodeJSON,
+		decode:    decodeJSON,
+	}
+	XML = &Format{
+		MediaType: echo.MIMEApplicationXML,
+		Aliases:   []string{echo.MIMETextXML},
+		encode:    encodeXML,
+		decode:    decodeXML,
+	}
+	MessagePack = &Format{
+		MediaType: MIMEMessagePack,
+		Aliases:   []string{"application/x-msgpack", "application/vnd.msgpack"},
+		encode:    encodeMessagePack,
+		decode:    decodeMessagePack,
+	}
+	Protobuf = &Format{
+		MediaType: MIMEProtobuf,
+		Aliases:   []string{"application/protobuf", "application/vnd.google.protobuf"},
+		encode:    encodeProtobuf,
+		decode:    decodeProtobuf,
+	}
+	CSV = &Format{
+		MediaType: MIMETextCSV,
+		encode:    encodeCSV,
+	}
+
+	// Documents are the formats of single resources, Lists adds CSV for
+	// lists of resources. JSON comes first as the default.
+	Documents = []*Format{JSON, XML, MessagePack, Protobuf}
+	Lists     = []*Format{JSON, XML, MessagePack, Protobuf, CSV}
+)
+
+// MediaTypes returns the media types of formats besides JSON, for the
+// OpenAPI document.
+func MediaTypes(formats []*Format) []string {
+	var mediaTypes []string
+	for _, format := range formats {
+		if format != JSON {
+			mediaTypes = append(mediaTypes, format.MediaType)
+		}
+	}
+	return mediaTypes
+}
+
+// Negotiate picks the response format of a route from the Accept header,
+// refusing with 406 when none of formats is acceptable. Request bodies are
+// refused with 415 unless one of formats decodes their Content-Type.
+func Negotiate(formats ...*Format) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength != 0 {
+				format := formatOf(formats, req.Header.Get(echo.HeaderContentType))
+				if format == nil || format.decode == nil {
+					return custErr.SetError(http.StatusUnsupportedMediaType, "Content-Type is not supported")
+				}
+			}
+
+			addVary(c.Response().Header(), echo.HeaderAccept)
+			format := accepted(formats, req.Header.Get(echo.HeaderAccept))
+			if format == nil {
+				return custErr.SetError(http.StatusNotAcceptable, "None of the accepted media types can be produced")
+			}
+
+			c.Set(formatKey, format)
+			return next(c)
+		}
+	}
+}
+
+// Render writes resp, a response envelope, in the format picked by
+// Negotiate, or as JSON on routes without negotiation.
+func Render(c echo.Context, status int, resp interface{}) error {
+	format, ok := c.Get(formatKey).(*Format)
+	if !ok {
+		format = JSON
+	}
+	return format.encode(c, status, resp)
+}
+
+// Bind decodes the request body into v by its Content-Type. The field names
+// of every format are those of the JSON request.
+func Bind(c echo.Context, v interface{}) error {
+	format := formatOf(Documents, c.Request().Header.Get(echo.HeaderContentType))
+	if format == nil {
+		return c.Bind(v)
+	}
+	return format.decode(c, v)
+}
+
+func (f *Format) matches(mediaType string) bool {
+	if mediaType == f.MediaType {
+		return true
+	}
+	for _, alias := range f.Aliases {
+		if mediaType == alias {
+			return true
+		}
+	}
+	return f == JSON && IsJSON(mediaType)
+}
+
+// IsJSON reports whether mediaType is JSON, including structured types such
+// as application/vnd.simple-api.v2+json.
+func IsJSON(mediaType string) bool {
+	return mediaType == echo.MIMEApplicationJSON ||
+		strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json")
+}
+
+func formatOf(formats []*Format, contentType string) *Format {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	for _, format := range formats {
+		if format.matches(mediaType) {
+			return format
+		}
+	}
+	return nil
+}
+
+func addVary(header http.Header, name string) {
+	for _, value := range header.Values(echo.HeaderVary) {
+		if strings.EqualFold(value, name) {
+			return
+		}
+	}
+	header.Add(echo.HeaderVary, name)
+}
+
+type acceptRange struct {
+	mediaType   string
+	quality     float64
+	specificity int
+}
+
+// accepted returns the format of the most preferred acceptable media range,
+// the first format when the header is empty.
+func accepted(formats []*Format, accept string) *Format {
+	if strings.TrimSpace(accept) == "" {
+		return formats[0]
+	}
+
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil || quality <= 0 {
+				continue
+			}
+		}
+
+		specificity := 2
+		if mediaType == "*/*" {
+			specificity = 0
+		} else if strings.HasSuffix(mediaType, "/*") {
+			specificity = 1
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality, specificity: specificity})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+		return ranges[i].specificity > ranges[j].specificity
+	})
+
+	for _, r := range ranges {
+		for _, format := range formats {
+			switch r.specificity {
+			case 0:
+				return format
+			case 1:
+				if strings.HasPrefix(format.MediaType, strings.TrimSuffix(r.mediaType, "*")) {
+					return format
+				}
+			default:
+				if format.matches(r.mediaType) {
+					return format
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	custErr "github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/library/response"
+	httpv1 "github.com/fadilahonespot/simple-api/proto/http/v1"
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type product struct {
+	ID     string  `json:"id"`
+	Title  string  `json:"title" xml:"title"`
+	Rating float64 `json:"rating" xml:"rating"`
+}
+
+var products = []product{
+	{ID: "22c8e385", Title: "Mie indomi Rasa ayam Soto", Rating: 8.1},
+	{ID: "a1b91cb9", Title: "Mie indomi, Rasa ayam Bawang", Rating: 9},
+}
+
+func serve(t *testing.T, method, accept, contentType string, body []byte, formats []*Format, resp interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		if appErr, ok := err.(*custErr.ApplicationError); ok {
+			c.NoContent(appErr.ErrorCode)
+			return
+		}
+		c.NoContent(http.StatusInternalServerError)
+	}
+	e.Add(method, "/products", func(c echo.Context) error {
+		return Render(c, http.StatusOK, resp)
+	}, Negotiate(formats...))
+
+	req := httptest.NewRequest(method, "/products", bytes.NewReader(body))
+	if accept != "" {
+		req.Header.Set(echo.HeaderAccept, accept)
+	}
+	if contentType != "" {
+		req.Header.Set(echo.HeaderContentType, contentType)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name            string
+		method          string
+		accept          string
+		contentType     string
+		body            string
+		formats         []*Format
+		wantStatus      int
+		wantContentType string
+	}{
+		{name: "no Accept header", method: http.MethodGet, formats: Documents, wantStatus: http.StatusOK, wantContentType: echo.MIMEApplicationJSON},
+		{name: "any media type", method: http.MethodGet, accept: "*/*", formats: Documents, wantStatus: http.StatusOK, wantContentType: echo.MIMEApplicationJSON},
+		{name: "versioned JSON", method: http.MethodGet, accept: "application/vnd.simple-api.v2+json", formats: Documents, wantStatus: http.StatusOK, wantContentType: echo.MIMEApplicationJSON},
+		{name: "xml alias", method: http.MethodGet, accept: "text/xml", formats: Documents, wantStatus: http.StatusOK, wantContentType: echo.MIMEApplicationXML},
+		{name: "highest quality wins", method: http.MethodGet, accept: "application/json;q=0.5, application/msgpack", formats: Documents, wantStatus: http.StatusOK, wantContentType: MIMEMessagePack},
+		{name: "specific range wins over wildcard", method: http.MethodGet, accept: "*/*, application/x-protobuf", formats: Documents, wantStatus: http.StatusOK, wantContentType: MIMEProtobuf},
+		{name: "csv of a list", method: http.MethodGet, accept: "text/csv", formats: Lists, wantStatus: http.StatusOK, wantContentType: MIMETextCSV},
+		{name: "csv of a single resource", method: http.MethodGet, accept: "text/csv", formats: Documents, wantStatus: http.StatusNotAcceptable},
+		{name: "refused media type", method: http.MethodGet, accept: "application/json;q=0, text/html", formats: Documents, wantStatus: http.StatusNotAcceptable},
+		{name: "supported request body", method: http.MethodPost, contentType: "application/xml; charset=utf-8", body: "<product/>", formats: Documents, wantStatus: http.StatusOK, wantContentType: echo.MIMEApplicationJSON},
+		{name: "request body without Content-Type", method: http.MethodPost, body: "{}", formats: Documents, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "unsupported request body", method: http.MethodPost, contentType: "text/csv", body: "title", formats: Lists, wantStatus: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, tt.method, tt.accept, tt.contentType, []byte(tt.body), tt.formats, response.ResponseSuccess(products))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Negotiate() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get(echo.HeaderContentType); tt.wantContentType != "" && !strings.HasPrefix(got, tt.wantContentType) {
+				t.Errorf("Negotiate() Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	list := response.HandleSuccessWithPagination(12, 2, 1, products)
+
+	tests := []struct {
+		name   string
+		accept string
+		resp   interface{}
+		check  func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "xml",
+			accept: echo.MIMEApplicationXML,
+			resp:   list,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				want := `<response><code>200</code><message>Success</message><data><item><id>22c8e385</id>`
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("Render() body = %s, want it to contain %s", rec.Body.String(), want)
+				}
+				if !strings.Contains(rec.Body.String(), `<pagination><page>1</page><limit>2</limit><totalData>12</totalData><totalPage>6</totalPage></pagination>`) {
+					t.Errorf("Render() body = %s, want the pagination", rec.Body.String())
+				}
+			},
+		},
+		{
+			name:   "messagepack",
+			accept: MIMEMessagePack,
+			resp:   list,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var got struct {
+					Code       int       `json:"code"`
+					Data       []product `json:"data"`
+					Pagination struct {
+						TotalData int `json:"totalData"`
+					} `json:"pagination"`
+				}
+				decoder := msgpack.NewDecoder(rec.Body)
+				decoder.SetCustomStructTag("json")
+				if err := decoder.Decode(&got); err != nil {
+					t.Fatalf("msgpack.Decode() error = %v", err)
+				}
+				if got.Code != http.StatusOK || len(got.Data) != 2 || got.Data[1] != products[1] || got.Pagination.TotalData != 12 {
+					t.Errorf("Render() = %+v", got)
+				}
+			},
+		},
+		{
+			name:   "protobuf",
+			accept: MIMEProtobuf,
+			resp:   list,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var got httpv1.Response
+				if err := proto.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+					t.Fatalf("proto.Unmarshal() error = %v", err)
+				}
+				items := got.GetData().GetListValue().GetValues()
+				if got.Code != http.StatusOK || len(items) != 2 || got.GetPagination().GetTotalPage() != 6 {
+					t.Fatalf("Render() = %v", &got)
+				}
+				if title := items[0].GetStructValue().GetFields()["title"].GetStringValue(); title != products[0].Title {
+					t.Errorf("Render() data[0].title = %q, want %q", title, products[0].Title)
+				}
+			},
+		},
+		{
+			name:   "csv",
+			accept: MIMETextCSV,
+			resp:   list,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				want := "id,title,rating\n22c8e385,Mie indomi Rasa ayam Soto,8.1\na1b91cb9,\"Mie indomi, Rasa ayam Bawang\",9\n"
+				if rec.Body.String() != want {
+					t.Errorf("Render() body = %q, want %q", rec.Body.String(), want)
+				}
+				if got := rec.Header().Get("X-Pagination-Total-Data"); got != "12" {
+					t.Errorf("Render() X-Pagination-Total-Data = %q, want 12", got)
+				}
+			},
+		},
+		{
+			name:   "csv of an error",
+			accept: MIMETextCSV,
+			resp:   response.ResponseError(http.StatusNotFound, "Product not found"),
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				if got := rec.Header().Get(echo.HeaderContentType); !strings.HasPrefix(got, echo.MIMEApplicationJSON) {
+					t.Errorf("Render() Content-Type = %q, want JSON", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, http.MethodGet, tt.accept, "", nil, Lists, tt.resp)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Render() status = %v, want %v", rec.Code, http.StatusOK)
+			}
+			tt.check(t, rec)
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	want := product{Title: "Mie Sedap rasa soto", Rating: 9}
+
+	jsonBody, _ := json.Marshal(want)
+	messagePackBody := func() []byte {
+		var buf bytes.Buffer
+		encoder := msgpack.NewEncoder(&buf)
+		encoder.SetCustomStructTag("json")
+		encoder.Encode(want)
+		return buf.Bytes()
+	}()
+	protobufBody := func() []byte {
+		request, _ := structpb.NewStruct(map[string]interface{}{"title": want.Title, "rating": want.Rating})
+		body, _ := proto.Marshal(request)
+		return body
+	}()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		wantErr     bool
+	}{
+		{name: "json", contentType: echo.MIMEApplicationJSON, body: jsonBody},
+		{name: "versioned json", contentType: "application/vnd.simple-api.v1+json", body: jsonBody},
+		{name: "xml", contentType: echo.MIMEApplicationXML, body: []byte("<product><title>Mie Sedap rasa soto</title><rating>9</rating></product>")},
+		{name: "messagepack", contentType: MIMEMessagePack, body: messagePackBody},
+		{name: "protobuf", contentType: MIMEProtobuf, body: protobufBody},
+		{name: "invalid protobuf", contentType: MIMEProtobuf, body: []byte("not protobuf"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/products", bytes.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, tt.contentType)
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			var got product
+			err := Bind(c, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Bind() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != want {
+				t.Errorf("Bind() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
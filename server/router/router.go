@@ -1,29 +1,329 @@
 package router
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/server/gql"
 	"github.com/fadilahonespot/simple-api/server/handler"
 	"github.com/fadilahonespot/simple-api/server/middleware"
+	"github.com/fadilahonespot/simple-api/server/openapi"
+	"github.com/fadilahonespot/simple-api/server/render"
+	"github.com/fadilahonespot/simple-api/usecase"
+	"github.com/fadilahonespot/simple-api/usecase/dto"
+	"github.com/fadilahonespot/simple-api/utils/health"
+	"github.com/fadilahonespot/simple-api/utils/idempotency"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/fadilahonespot/simple-api/utils/metrics"
+	"github.com/fadilahonespot/simple-api/utils/tracing"
 	"github.com/labstack/echo/v4"
 )
 
+// apiVersions are served under /v1 and /v2. Unversioned paths of
+// versionedResources are served by the version asked for in the Accept
+// header, or else the default version.
+var (
+	apiVersions        = []string{"v1", "v2"}
+	versionedResources = []string{"products"}
+)
+
 type DefaultRouter struct {
-	ProductHandler *handler.ProductHandler
+	Config           config.Config
+	ProductHandler   *handler.ProductHandler
+	ProductV2Handler *handler.ProductV2Handler
+	HealthHandler    *handler.HealthHandler
+	GraphQLHandler   *handler.GraphQLHandler
+	OpenAPI          *openapi.Builder
+	IdempotencyStore idempotency.Store
+	Logger           logger.Logger
+
+	ProductEventHandler *handler.ProductEventHandler
+	WebSocketHandler    *handler.WebSocketHandler
+	LogLevelHandler     *handler.LogLevelHandler
 }
 
 func (d *DefaultRouter) Validate() {
 	if d.ProductHandler == nil {
 		panic("product handler is nil")
 	}
+
+	if d.ProductV2Handler == nil {
+		panic("product v2 handler is nil")
+	}
+
+	if d.HealthHandler == nil {
+		panic("health handler is nil")
+	}
+
+	if d.ProductEventHandler == nil {
+		panic("product event handler is nil")
+	}
+
+	if d.LogLevelHandler == nil {
+		panic("log level handler is nil")
+	}
+
+	if d.Config.Idempotency.Enabled && d.IdempotencyStore == nil {
+		panic("idempotency store is nil")
+	}
+
+	if d.Logger == nil {
+		panic("logger is nil")
+	}
 }
 
 func (d *DefaultRouter) NewRouter(e *echo.Echo) *DefaultRouter {
-	middleware.SetupMiddleware(e)
-	
-	e.POST("/products", d.ProductHandler.AddProduct)
-	e.GET("/products", d.ProductHandler.GetListProduct)
-	e.GET("/products/:productId", d.ProductHandler.GetProductDetail)
-	e.PUT("/products/:productId", d.ProductHandler.UpdateProduct)
-	e.DELETE("/products/:productId", d.ProductHandler.DeleteProduct)
-	
+	d.OpenAPI = openapi.NewBuilder(openapi.Info{
+		Title:   tracing.ServiceName,
+		Version: tracing.ServiceVersion,
+	})
+	docs := d.OpenAPI
+
+	middleware.SetupMiddleware(e, d.Config, docs, d.IdempotencyStore, d.Logger)
+
+	docs.Add(e.GET("/healthz", d.HealthHandler.Liveness), openapi.Spec{
+		Summary:  "Liveness probe",
+		Tags:     []string{"health"},
+		Response: health.Report{},
+		Responses: map[int]interface{}{
+			http.StatusServiceUnavailable: health.Report{},
+		},
+	})
+	docs.Add(e.GET("/readyz", d.HealthHandler.Readiness), openapi.Spec{
+		Summary:  "Readiness probe",
+		Tags:     []string{"health"},
+		Response: health.Report{},
+		Responses: map[int]interface{}{
+			http.StatusServiceUnavailable: health.Report{},
+		},
+	})
+	docs.Add(e.GET("/metrics", echo.WrapHandler(metrics.Handler())), openapi.Spec{
+		OperationID: "metrics",
+		Summary:     "Prometheus metrics",
+		Tags:        []string{"observability"},
+		Response:    "",
+		ContentType: "text/plain",
+	})
+	docs.Add(e.GET("/openapi.json", docs.Handler()), openapi.Spec{
+		OperationID: "openapi",
+		Summary:     "OpenAPI document",
+		Tags:        []string{"docs"},
+		Response:    map[string]interface{}{},
+	})
+	docs.Add(e.GET("/docs", openapi.UIHandler()), openapi.Spec{
+		OperationID: "docs",
+		Summary:     "API documentation page",
+		Tags:        []string{"docs"},
+		Response:    "",
+		ContentType: echo.MIMETextHTML,
+	})
+
+	for _, version := range apiVersions {
+		d.addProductRoutes(e.Group("/"+version, middleware.APIVersion(d.Config.Versioning, version)), version)
+	}
+	e.Pre(middleware.VersionRouting(d.Config.Versioning, apiVersions, versionedResources))
+
+	if d.WebSocketHandler != nil {
+		docs.Add(e.GET("/ws", d.WebSocketHandler.Connect), openapi.Spec{
+			OperationID: "websocket",
+			Summary:     "Subscribe to product changes over WebSocket",
+			Description: "Upgrades to a WebSocket. Authenticate with a bearer token in the Authorization header or the access_token query parameter, " +
+				"then send subscribe and unsubscribe messages to receive product.created, product.updated and product.deleted events.",
+			Tags: []string{"products"},
+			Parameters: []openapi.Parameter{
+				openapi.QueryParam("access_token", "Bearer token for clients that cannot set the Authorization header", &openapi.Schema{Type: "string"}),
+			},
+			Status: http.StatusSwitchingProtocols,
+			Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusServiceUnavailable},
+		})
+	}
+
+	if d.GraphQLHandler != nil {
+		docs.Add(e.POST("/graphql", d.GraphQLHandler.Query), openapi.Spec{
+			OperationID: "graphql",
+			Summary:     "GraphQL endpoint",
+			Tags:        []string{"graphql"},
+			Request:     gql.Request{},
+			Response:    gql.Response{},
+			Errors:      []int{http.StatusBadRequest},
+		})
+
+		if d.Config.GraphQL.GraphiQL {
+			docs.Add(e.GET("/graphiql", gql.GraphiQLHandler()), openapi.Spec{
+				OperationID: "graphiql",
+				Summary:     "GraphiQL IDE",
+				Tags:        []string{"graphql"},
+				Response:    "",
+				ContentType: echo.MIMETextHTML,
+			})
+		}
+	}
+
+	admin := e.Group("/admin", middleware.AdminAuth(d.Config.Admin.Tokens))
+	docs.Add(admin.GET("/log-levels", d.LogLevelHandler.GetLevels), openapi.Spec{
+		OperationID: "getLogLevels",
+		Summary:     "Get log levels",
+		Description: "Requires a bearer token from ADMIN_TOKENS.",
+		Tags:        []string{"admin"},
+		Response:    logger.LevelsReport{},
+		Envelope:    openapi.EnvelopeData,
+		Errors:      []int{http.StatusUnauthorized},
+	})
+	docs.Add(admin.PUT("/log-levels", d.LogLevelHandler.SetLevel), openapi.Spec{
+		OperationID: "setLogLevel",
+		Summary:     "Set a log level",
+		Description: "Sets the level of a logger, or the default level when package is empty. " +
+			"An empty level resets the logger to the default level. Requires a bearer token from ADMIN_TOKENS.",
+		Tags:     []string{"admin"},
+		Request:  logger.LevelChange{},
+		Response: logger.LevelsReport{},
+		Envelope: openapi.EnvelopeData,
+		Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized},
+	})
+
 	return d
-}
\ No newline at end of file
+}
+
+// productRoutes are the product endpoints of an API version. Each version
+// starts from the previous one and replaces what changed.
+type productRoutes struct {
+	add, list, batchGet, detail, update, delete, events echo.HandlerFunc
+	detailResponse                                      interface{}
+}
+
+func (d *DefaultRouter) productRoutes(version string) productRoutes {
+	routes := productRoutes{
+		add:            d.ProductHandler.AddProduct,
+		list:           d.ProductHandler.GetListProduct,
+		batchGet:       d.ProductHandler.BatchGetProducts,
+		detail:         d.ProductHandler.GetProductDetail,
+		update:         d.ProductHandler.UpdateProduct,
+		delete:         d.ProductHandler.DeleteProduct,
+		events:         d.ProductEventHandler.StreamEvents,
+		detailResponse: dto.DetailProductResponse{},
+	}
+	if version == "v1" {
+		return routes
+	}
+
+	routes.detail = d.ProductV2Handler.GetProductDetail
+	routes.detailResponse = dto.ProductResponseV2{}
+	return routes
+}
+
+func (d *DefaultRouter) addProductRoutes(g *echo.Group, version string) {
+	routes := d.productRoutes(version)
+	productId := openapi.PathParam("productId", "Product ID", &openapi.Schema{Type: "string", Format: "uuid"})
+	fields := openapi.QueryParam("fields", "Comma separated fields to return, every field when empty", &openapi.Schema{Type: "string"})
+	expand := openapi.QueryParam("expand", "Comma separated sections to add: "+dto.SectionAudit, &openapi.Schema{Type: "string"})
+
+	d.addVersioned(version, g.POST("/products", routes.add, render.Negotiate(render.Documents...)), openapi.Spec{
+		OperationID: "addProduct",
+		Summary:     "Add product",
+		Tags:        []string{"products"},
+		Produces:    render.MediaTypes(render.Documents),
+		Consumes:    render.MediaTypes(render.Documents),
+		Request:     dto.ProductRequest{},
+		Envelope:    openapi.EnvelopeData,
+		Errors:      []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity},
+	})
+	d.addVersioned(version, g.GET("/products", routes.list, render.Negotiate(render.Lists...)), openapi.Spec{
+		OperationID: "getListProduct",
+		Summary:     "Get list product",
+		Description: "With ids, the products of the IDs are returned as by batchGetProducts instead of a page.",
+		Tags:        []string{"products"},
+		Produces:    render.MediaTypes(render.Lists),
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("ids", fmt.Sprintf("Comma separated product IDs to fetch, at most %d", usecase.MaxBatchIDs), &openapi.Schema{Type: "string"}),
+			openapi.QueryParam("page", "Page number", &openapi.Schema{Type: "integer", Minimum: floatPtr(1)}),
+			openapi.QueryParam("limit", "Page size", &openapi.Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(30)}),
+			openapi.QueryParam("title", "Filter by title", &openapi.Schema{Type: "string"}),
+			openapi.QueryParam("rating", "Filter by rating", &openapi.Schema{Type: "number"}),
+			fields, expand,
+		},
+		Response: []dto.ProductListResponse{},
+		Envelope: openapi.EnvelopePagination,
+		Fieldset: dto.ProductListResponse{},
+		Alternatives: []openapi.Alternative{
+			{Response: []dto.ProductBatchItem{}, Envelope: openapi.EnvelopeData},
+		},
+		Errors: []int{http.StatusBadRequest},
+	})
+	d.addVersioned(version, g.POST("/products/batch-get", routes.batchGet, render.Negotiate(render.Documents...)), openapi.Spec{
+		OperationID: "batchGetProducts",
+		Summary:     "Get products by ID",
+		Description: fmt.Sprintf("Returns the products of up to %d IDs in the order they were asked for, "+
+			"with found set to false and a null product for IDs without a product.", usecase.MaxBatchIDs),
+		Tags:       []string{"products"},
+		Produces:   render.MediaTypes(render.Documents),
+		Consumes:   render.MediaTypes(render.Documents),
+		Parameters: []openapi.Parameter{fields, expand},
+		Request:    dto.ProductBatchRequest{},
+		Response:   []dto.ProductBatchItem{},
+		Envelope:   openapi.EnvelopeData,
+		Fieldset:   dto.ProductListResponse{},
+		Errors:     []int{http.StatusBadRequest},
+	})
+	d.addVersioned(version, g.GET("/products/events", routes.events), openapi.Spec{
+		OperationID: "streamProductEvents",
+		Summary:     "Stream product changes",
+		Description: "Server-Sent Events stream of product.created, product.updated and product.deleted events. " +
+			"Resume with the Last-Event-ID header; a reset event means events were missed and the catalog should be refetched.",
+		Tags: []string{"products"},
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("types", "Comma separated event types to receive: created, updated, deleted", &openapi.Schema{Type: "string"}),
+			openapi.QueryParam("productId", "Only receive events of this product", &openapi.Schema{Type: "string", Format: "uuid"}),
+			openapi.QueryParam("lastEventId", "Resume after this event when the Last-Event-ID header cannot be set", &openapi.Schema{Type: "integer", Minimum: floatPtr(0)}),
+		},
+		Response:    "",
+		ContentType: handler.MIMETextEventStream,
+		Errors:      []int{http.StatusBadRequest},
+	})
+	d.addVersioned(version, g.GET("/products/:productId", routes.detail, render.Negotiate(render.Documents...)), openapi.Spec{
+		OperationID: "getProductDetail",
+		Summary:     "Get product detail",
+		Tags:        []string{"products"},
+		Produces:    render.MediaTypes(render.Documents),
+		Parameters:  []openapi.Parameter{productId, fields, expand},
+		Response:    routes.detailResponse,
+		Envelope:    openapi.EnvelopeData,
+		Fieldset:    routes.detailResponse,
+		Errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+	})
+	d.addVersioned(version, g.PUT("/products/:productId", routes.update, render.Negotiate(render.Documents...)), openapi.Spec{
+		OperationID: "updateProduct",
+		Summary:     "Update product",
+		Tags:        []string{"products"},
+		Produces:    render.MediaTypes(render.Documents),
+		Consumes:    render.MediaTypes(render.Documents),
+		Parameters:  []openapi.Parameter{productId},
+		Request:     dto.ProductRequest{},
+		Envelope:    openapi.EnvelopeData,
+		Errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+	})
+	d.addVersioned(version, g.DELETE("/products/:productId", routes.delete, render.Negotiate(render.Documents...)), openapi.Spec{
+		OperationID: "deleteProduct",
+		Summary:     "Delete product",
+		Tags:        []string{"products"},
+		Produces:    render.MediaTypes(render.Documents),
+		Parameters:  []openapi.Parameter{productId},
+		Envelope:    openapi.EnvelopeData,
+		Errors:      []int{http.StatusNotFound},
+	})
+}
+
+// addVersioned documents a route of version. Operation IDs of versions after
+// v1 are suffixed, such as getProductDetailV2, to stay unique.
+func (d *DefaultRouter) addVersioned(version string, r *echo.Route, spec openapi.Spec) {
+	if version != apiVersions[0] {
+		spec.OperationID += strings.ToUpper(version)
+	}
+	_, spec.Deprecated = d.Config.Versioning.DeprecatedAt(version)
+	d.OpenAPI.Add(r, spec)
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
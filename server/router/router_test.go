@@ -0,0 +1,112 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/server/gql"
+	"github.com/fadilahonespot/simple-api/server/handler"
+	"github.com/fadilahonespot/simple-api/server/openapi"
+	"github.com/fadilahonespot/simple-api/server/ws"
+	"github.com/fadilahonespot/simple-api/usecase/mocks"
+	"github.com/fadilahonespot/simple-api/utils/events"
+	"github.com/fadilahonespot/simple-api/utils/health"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+func TestDefaultRouter_OpenAPIMatchesRoutes(t *testing.T) {
+	productHandler := handler.NewProductHandler(mocks.NewProductUsecase(t), logger.Default())
+	productV2Handler := handler.NewProductV2Handler(mocks.NewProductUsecase(t), logger.Default())
+	healthHandler := handler.NewHealthHandler(health.NewRegistry(0), logger.Default())
+	executor, err := gql.NewExecutor(config.GraphQLConfig{}, mocks.NewProductUsecase(t))
+	if err != nil {
+		t.Fatalf("gql.NewExecutor() error = %v", err)
+	}
+	graphQLHandler := handler.NewGraphQLHandler(executor, logger.Default())
+	productEventHandler := handler.NewProductEventHandler(events.NewBroker(0, 1), time.Second, logger.Default())
+	webSocketHandler := handler.NewWebSocketHandler(ws.NewHub(config.WebSocketConfig{}, events.NewBroker(0, 1), logger.Default()), logger.Default())
+	logLevelHandler := handler.NewLogLevelHandler(logger.NewLevels(logger.LevelInfo), logger.Default())
+
+	e := echo.New()
+	router := &DefaultRouter{
+		Config: config.Config{
+			Idempotency: config.IdempotencyConfig{Header: "Idempotency-Key"},
+			GraphQL:     config.GraphQLConfig{GraphiQL: true},
+		},
+		ProductHandler:   &productHandler,
+		ProductV2Handler: &productV2Handler,
+		HealthHandler:    &healthHandler,
+		GraphQLHandler:   &graphQLHandler,
+
+		ProductEventHandler: &productEventHandler,
+		WebSocketHandler:    &webSocketHandler,
+		LogLevelHandler:     &logLevelHandler,
+		Logger:              logger.Default(),
+	}
+	router.NewRouter(e)
+
+	routes := make(map[string]bool)
+	for _, r := range e.Routes() {
+		if r.Method == echo.RouteNotFound {
+			continue
+		}
+		routes[r.Method+" "+openapi.Path(r.Path)] = true
+	}
+
+	documented := make(map[string]bool)
+	for path, item := range router.OpenAPI.Document().Paths {
+		operations := map[string]*openapi.Operation{
+			http.MethodGet:    item.Get,
+			http.MethodPost:   item.Post,
+			http.MethodPut:    item.Put,
+			http.MethodPatch:  item.Patch,
+			http.MethodDelete: item.Delete,
+		}
+		for method, operation := range operations {
+			if operation != nil {
+				documented[method+" "+path] = true
+			}
+		}
+	}
+
+	for route := range routes {
+		if !documented[route] {
+			t.Errorf("route %s is not documented in the OpenAPI document", route)
+		}
+	}
+	for route := range documented {
+		if !routes[route] {
+			t.Errorf("OpenAPI document describes %s which is not routed", route)
+		}
+	}
+}
+
+func TestDefaultRouter_OpenAPIOperationIDs(t *testing.T) {
+	productHandler := handler.NewProductHandler(mocks.NewProductUsecase(t), logger.Default())
+	productV2Handler := handler.NewProductV2Handler(mocks.NewProductUsecase(t), logger.Default())
+	healthHandler := handler.NewHealthHandler(health.NewRegistry(0), logger.Default())
+	productEventHandler := handler.NewProductEventHandler(events.NewBroker(0, 1), time.Second, logger.Default())
+	logLevelHandler := handler.NewLogLevelHandler(logger.NewLevels(logger.LevelInfo), logger.Default())
+
+	router := &DefaultRouter{ProductHandler: &productHandler, ProductV2Handler: &productV2Handler, HealthHandler: &healthHandler, ProductEventHandler: &productEventHandler, LogLevelHandler: &logLevelHandler, Logger: logger.Default()}
+	router.NewRouter(echo.New())
+
+	seen := make(map[string]string)
+	for path, item := range router.OpenAPI.Document().Paths {
+		for _, operation := range []*openapi.Operation{item.Get, item.Post, item.Put, item.Patch, item.Delete} {
+			if operation == nil {
+				continue
+			}
+			if operation.OperationID == "" {
+				t.Errorf("operation on %s has no operationId", path)
+			}
+			if other, ok := seen[operation.OperationID]; ok {
+				t.Errorf("operationId %s is used by %s and %s", operation.OperationID, other, path)
+			}
+			seen[operation.OperationID] = path
+		}
+	}
+}
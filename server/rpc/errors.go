@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/library/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var httpToCode = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.Aborted,
+	http.StatusPreconditionFailed:  codes.FailedPrecondition,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+// statusError converts the library ApplicationError returned by the usecases
+// to a gRPC status error. Any other error is reported as Internal.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	appErr, ok := err.(*errors.ApplicationError)
+	if !ok {
+		return status.Error(codes.Internal, http.StatusText(http.StatusInternalServerError))
+	}
+
+	code, ok := httpToCode[appErr.ErrorCode]
+	if !ok {
+		code = codes.Internal
+		if appErr.ErrorCode < http.StatusInternalServerError {
+			code = codes.Unknown
+		}
+	}
+	return status.Error(code, appErr.Error())
+}
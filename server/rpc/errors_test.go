@@ -0,0 +1,39 @@
+package rpc
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	libErrors "github.com/fadilahonespot/library/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_statusError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{name: "nil", err: nil, wantCode: codes.OK},
+		{name: "bad request", err: libErrors.SetError(http.StatusBadRequest, "Product is already exist"), wantCode: codes.InvalidArgument, wantMessage: "Product is already exist"},
+		{name: "not found", err: libErrors.SetError(http.StatusNotFound, "Not Found"), wantCode: codes.NotFound, wantMessage: "Not Found"},
+		{name: "conflict", err: libErrors.SetError(http.StatusConflict, "Conflict"), wantCode: codes.Aborted, wantMessage: "Conflict"},
+		{name: "internal", err: libErrors.SetError(http.StatusInternalServerError, "Internal Server Error"), wantCode: codes.Internal, wantMessage: "Internal Server Error"},
+		{name: "unmapped client error", err: libErrors.SetError(http.StatusTeapot, "teapot"), wantCode: codes.Unknown, wantMessage: "teapot"},
+		{name: "plain error", err: errors.New("boom"), wantCode: codes.Internal, wantMessage: "Internal Server Error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := status.Convert(statusError(tt.err))
+			if got.Code() != tt.wantCode {
+				t.Errorf("statusError() code = %v, want %v", got.Code(), tt.wantCode)
+			}
+			if got.Message() != tt.wantMessage {
+				t.Errorf("statusError() message = %v, want %v", got.Message(), tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/fadilahonespot/library/logres"
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/fadilahonespot/simple-api/utils/metrics"
+	"github.com/fadilahonespot/simple-api/utils/requestid"
+	"github.com/fadilahonespot/simple-api/utils/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type panicReport struct {
+	RequestID string `json:"requestId"`
+	Method    string `json:"method"`
+	Panic     string `json:"panic"`
+	Stack     string `json:"stack"`
+}
+
+func unaryLoggerInterceptor(port int, requestIDConfig config.RequestIDConfig, requestLog logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = setLogger(ctx, port, requestIDConfig, requestLog, info.FullMethod)
+		return handler(ctx, req)
+	}
+}
+
+func streamLoggerInterceptor(port int, requestIDConfig config.RequestIDConfig, requestLog logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := setLogger(stream.Context(), port, requestIDConfig, requestLog, info.FullMethod)
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func setLogger(ctx context.Context, port int, requestIDConfig config.RequestIDConfig, requestLog logger.Logger, fullMethod string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	threadID := requestid.Resolve(ctx, requestIDConfig, func(name string) string {
+		if values := md.Get(name); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	})
+	if requestIDConfig.ResponseHeader != "" {
+		grpc.SetHeader(ctx, metadata.Pairs(requestIDConfig.ResponseHeader, threadID))
+	}
+
+	ctx = logres.SetCtxLogger(ctx, logres.Context{
+		ServiceName:    tracing.ServiceName,
+		ServiceVersion: tracing.ServiceVersion,
+		ServicePort:    port,
+		ThreadID:       threadID,
+		ReqMethod:      "GRPC",
+		ReqURI:         fullMethod,
+	})
+
+	requestLog.Info(ctx, "Incoming Request")
+	return ctx
+}
+
+// unaryRecoverInterceptor turns a panic in a handler into an Internal error
+// instead of crashing the process. It must run after unaryLoggerInterceptor
+// so the report carries the thread ID.
+func unaryRecoverInterceptor(log logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer recoverPanic(ctx, log, info.FullMethod, &err)
+		return handler(ctx, req)
+	}
+}
+
+func streamRecoverInterceptor(log logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer recoverPanic(stream.Context(), log, info.FullMethod, &err)
+		return handler(srv, stream)
+	}
+}
+
+// recoverPanic must be deferred directly by the interceptor.
+func recoverPanic(ctx context.Context, log logger.Logger, fullMethod string, err *error) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	log.Error(ctx, "Recovered from panic", panicReport{
+		RequestID: logres.GetCtxLogger(ctx).ThreadID,
+		Method:    fullMethod,
+		Panic:     fmt.Sprint(recovered),
+		Stack:     string(debug.Stack()),
+	})
+	metrics.GRPCPanicsTotal.WithLabelValues(fullMethod).Inc()
+	*err = status.Error(codes.Internal, "Internal Server Error")
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,42 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	productv1 "github.com/fadilahonespot/simple-api/proto/product/v1"
+	"github.com/fadilahonespot/simple-api/usecase/mocks"
+	"github.com/fadilahonespot/simple-api/utils/fieldset"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_recoverPanic(t *testing.T) {
+	id := uuid.New().String()
+	productUsecase := mocks.NewProductUsecase(t)
+	productUsecase.On("GetDetailProduct", mock.Anything, id, fieldset.Fieldset{}).Run(func(args mock.Arguments) {
+		panic("boom")
+	}).Twice()
+	productUsecase.On("GetListProduct", mock.Anything, mock.Anything, fieldset.Fieldset{}).Run(func(args mock.Arguments) {
+		panic("boom")
+	}).Once()
+
+	client := productv1.NewProductServiceClient(newTestClient(t, productUsecase))
+	for i := 0; i < 2; i++ {
+		_, err := client.GetProduct(context.Background(), &productv1.GetProductRequest{Id: id})
+		if code := status.Code(err); code != codes.Internal {
+			t.Fatalf("productService.GetProduct() call %d code = %v, want %v", i+1, code, codes.Internal)
+		}
+	}
+
+	stream, err := client.ListProducts(context.Background(), &productv1.ListProductsRequest{})
+	if err != nil {
+		t.Fatalf("productService.ListProducts() error = %v", err)
+	}
+	_, err = stream.Recv()
+	if code := status.Code(err); code != codes.Internal {
+		t.Errorf("productService.ListProducts() recv code = %v, want %v", code, codes.Internal)
+	}
+}
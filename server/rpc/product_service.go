@@ -0,0 +1,155 @@
+package rpc
+
+import (
+	"context"
+
+	productv1 "github.com/fadilahonespot/simple-api/proto/product/v1"
+	"github.com/fadilahonespot/simple-api/usecase"
+	"github.com/fadilahonespot/simple-api/usecase/dto"
+	"github.com/fadilahonespot/simple-api/utils/fieldset"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/fadilahonespot/simple-api/utils/paginate"
+	"github.com/go-playground/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 30
+)
+
+type productService struct {
+	productv1.UnimplementedProductServiceServer
+	productUsecase usecase.ProductUsecase
+	validate       *validator.Validate
+	log            logger.Logger
+}
+
+func NewProductService(productUsecase usecase.ProductUsecase, log logger.Logger) productv1.ProductServiceServer {
+	return &productService{productUsecase: productUsecase, validate: validator.New(), log: log}
+}
+
+func (s *productService) CreateProduct(ctx context.Context, req *productv1.CreateProductRequest) (resp *productv1.CreateProductResponse, err error) {
+	productReq := dto.ProductRequest{
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		Rating:      req.GetRating(),
+		Image:       req.GetImage(),
+	}
+	err = s.validateRequest(ctx, productReq)
+	if err != nil {
+		return
+	}
+
+	err = s.productUsecase.CreateProduct(ctx, productReq)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &productv1.CreateProductResponse{}, nil
+}
+
+func (s *productService) GetProduct(ctx context.Context, req *productv1.GetProductRequest) (resp *productv1.Product, err error) {
+	data, err := s.productUsecase.GetDetailProduct(ctx, req.GetId(), fieldset.Fieldset{})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &productv1.Product{
+		Id:          data.ID.String(),
+		Title:       data.Title,
+		Description: data.Description,
+		Rating:      data.Rating,
+		Image:       data.Image,
+		CreatedAt:   timestamppb.New(data.CreatedAt),
+		UpdatedAt:   timestamppb.New(data.UpdatedAt),
+	}, nil
+}
+
+func (s *productService) ListProducts(req *productv1.ListProductsRequest, stream productv1.ProductService_ListProductsServer) (err error) {
+	ctx := stream.Context()
+	params := paginate.Pagination{
+		Page:   1,
+		Limit:  int(req.GetPageSize()),
+		Title:  req.GetTitle(),
+		Rating: req.GetRating(),
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultPageSize
+	}
+	if params.Limit > maxPageSize {
+		params.Limit = maxPageSize
+	}
+
+	var sent int64
+	for {
+		data, count, err := s.productUsecase.GetListProduct(ctx, params, fieldset.Fieldset{})
+		if err != nil {
+			return statusError(err)
+		}
+
+		for _, product := range data {
+			err = stream.Send(&productv1.Product{
+				Id:          product.ID.String(),
+				Title:       product.Title,
+				Description: product.Description,
+				Rating:      product.Rating,
+				Image:       product.Image,
+			})
+			if err != nil {
+				s.log.Error(ctx, "error sending product", err.Error())
+				return err
+			}
+			sent++
+		}
+
+		if len(data) < params.Limit || sent >= count {
+			return nil
+		}
+		params.Page++
+	}
+}
+
+func (s *productService) UpdateProduct(ctx context.Context, req *productv1.UpdateProductRequest) (resp *productv1.UpdateProductResponse, err error) {
+	productReq := dto.ProductRequest{
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		Rating:      req.GetRating(),
+		Image:       req.GetImage(),
+	}
+	err = s.validateRequest(ctx, productReq)
+	if err != nil {
+		return
+	}
+
+	err = s.productUsecase.UpdateProduct(ctx, req.GetId(), productReq)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &productv1.UpdateProductResponse{}, nil
+}
+
+func (s *productService) DeleteProduct(ctx context.Context, req *productv1.DeleteProductRequest) (resp *productv1.DeleteProductResponse, err error) {
+	err = s.productUsecase.DeleteProduct(ctx, req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &productv1.DeleteProductResponse{}, nil
+}
+
+func (s *productService) validateRequest(ctx context.Context, req interface{}) error {
+	err := s.validate.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	s.log.Error(ctx, "error validating", err.Error())
+	if validationErrors, ok := err.(validator.ValidationErrors); ok && len(validationErrors) > 0 {
+		return status.Errorf(codes.InvalidArgument, "field validator for input %v failed on the %v tag", validationErrors[0].Field(), validationErrors[0].ActualTag())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
@@ -0,0 +1,193 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	libErrors "github.com/fadilahonespot/library/errors"
+	"github.com/fadilahonespot/simple-api/config"
+	productv1 "github.com/fadilahonespot/simple-api/proto/product/v1"
+	"github.com/fadilahonespot/simple-api/usecase/dto"
+	"github.com/fadilahonespot/simple-api/usecase/mocks"
+	"github.com/fadilahonespot/simple-api/utils/fieldset"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/fadilahonespot/simple-api/utils/paginate"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T, productUsecase *mocks.ProductUsecase) *grpc.ClientConn {
+	logger.NewLogger(config.LoggerConfig{})
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(config.Config{}, productUsecase, logger.New(logger.NewRecorder(), nil))
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Shutdown(context.Background()) })
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func Test_productService_CreateProduct(t *testing.T) {
+	tests := []struct {
+		name             string
+		req              *productv1.CreateProductRequest
+		createProductErr error
+		callUsecase      bool
+		wantCode         codes.Code
+	}{
+		{
+			name:     "invalid request",
+			req:      &productv1.CreateProductRequest{Description: "Taburan ayam gurih"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:             "duplicate product",
+			req:              &productv1.CreateProductRequest{Title: "Mie", Description: "Taburan ayam gurih"},
+			createProductErr: libErrors.SetError(http.StatusBadRequest, "Product is already exist"),
+			callUsecase:      true,
+			wantCode:         codes.InvalidArgument,
+		},
+		{
+			name:        "success",
+			req:         &productv1.CreateProductRequest{Title: "Mie", Description: "Taburan ayam gurih", Rating: 4.5},
+			callUsecase: true,
+			wantCode:    codes.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productUsecase := mocks.NewProductUsecase(t)
+			if tt.callUsecase {
+				productUsecase.On("CreateProduct", mock.Anything, dto.ProductRequest{
+					Title:       tt.req.Title,
+					Description: tt.req.Description,
+					Rating:      tt.req.Rating,
+				}).Return(tt.createProductErr).Once()
+			}
+
+			client := productv1.NewProductServiceClient(newTestClient(t, productUsecase))
+			_, err := client.CreateProduct(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("productService.CreateProduct() code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func Test_productService_GetProduct(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name     string
+		resp     dto.DetailProductResponse
+		err      error
+		wantCode codes.Code
+	}{
+		{
+			name:     "not found",
+			err:      libErrors.SetError(http.StatusNotFound, http.StatusText(http.StatusNotFound)),
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "success",
+			resp:     dto.DetailProductResponse{ID: id, Title: "Mie"},
+			wantCode: codes.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productUsecase := mocks.NewProductUsecase(t)
+			productUsecase.On("GetDetailProduct", mock.Anything, id.String(), fieldset.Fieldset{}).Return(tt.resp, tt.err).Once()
+
+			client := productv1.NewProductServiceClient(newTestClient(t, productUsecase))
+			got, err := client.GetProduct(context.Background(), &productv1.GetProductRequest{Id: id.String()})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("productService.GetProduct() code = %v, want %v", code, tt.wantCode)
+			}
+			if err == nil && (got.Id != id.String() || got.Title != tt.resp.Title) {
+				t.Errorf("productService.GetProduct() = %v", got)
+			}
+		})
+	}
+}
+
+func Test_productService_ListProducts(t *testing.T) {
+	page := func(n int) []dto.ProductListResponse {
+		products := make([]dto.ProductListResponse, n)
+		for i := range products {
+			products[i] = dto.ProductListResponse{ID: uuid.New(), Title: "Mie"}
+		}
+		return products
+	}
+
+	productUsecase := mocks.NewProductUsecase(t)
+	params := paginate.Pagination{Page: 1, Limit: 2, Title: "Mie"}
+	productUsecase.On("GetListProduct", mock.Anything, params, fieldset.Fieldset{}).Return(page(2), int64(5), nil).Once()
+	params.Page = 2
+	productUsecase.On("GetListProduct", mock.Anything, params, fieldset.Fieldset{}).Return(page(2), int64(5), nil).Once()
+	params.Page = 3
+	productUsecase.On("GetListProduct", mock.Anything, params, fieldset.Fieldset{}).Return(page(1), int64(5), nil).Once()
+
+	client := productv1.NewProductServiceClient(newTestClient(t, productUsecase))
+	stream, err := client.ListProducts(context.Background(), &productv1.ListProductsRequest{Title: "Mie", PageSize: 2})
+	if err != nil {
+		t.Fatalf("productService.ListProducts() error = %v", err)
+	}
+
+	received := 0
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("productService.ListProducts() recv error = %v", err)
+		}
+		received++
+	}
+	if received != 5 {
+		t.Errorf("productService.ListProducts() received %v products, want 5", received)
+	}
+}
+
+func Test_productService_DeleteProduct(t *testing.T) {
+	productUsecase := mocks.NewProductUsecase(t)
+	productUsecase.On("DeleteProduct", mock.Anything, "missing").Return(libErrors.SetError(http.StatusNotFound, http.StatusText(http.StatusNotFound))).Once()
+
+	client := productv1.NewProductServiceClient(newTestClient(t, productUsecase))
+	_, err := client.DeleteProduct(context.Background(), &productv1.DeleteProductRequest{Id: "missing"})
+	if got := status.Code(err); got != codes.NotFound {
+		t.Errorf("productService.DeleteProduct() code = %v, want %v", got, codes.NotFound)
+	}
+}
+
+func TestServer_Health(t *testing.T) {
+	client := healthpb.NewHealthClient(newTestClient(t, mocks.NewProductUsecase(t)))
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: productv1.ProductService_ServiceDesc.ServiceName,
+	})
+	if err != nil {
+		t.Fatalf("Health.Check() error = %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Health.Check() status = %v, want SERVING", resp.Status)
+	}
+}
@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"context"
+	"net"
+
+	"github.com/fadilahonespot/simple-api/config"
+	productv1 "github.com/fadilahonespot/simple-api/proto/product/v1"
+	"github.com/fadilahonespot/simple-api/usecase"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+type Server interface {
+	Serve(listener net.Listener) error
+	// Shutdown reports NOT_SERVING to health checks and drains in-flight
+	// calls, stopping the server forcefully once ctx is done.
+	Shutdown(ctx context.Context) error
+}
+
+type defaultServer struct {
+	server *grpc.Server
+	health *health.Server
+}
+
+func NewServer(cfg config.Config, productUsecase usecase.ProductUsecase, log logger.Logger) Server {
+	// The request log is sampled since it writes an entry for every call.
+	requestLog := log.Sampled()
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryLoggerInterceptor(cfg.GRPC.Port, cfg.RequestID, requestLog), unaryRecoverInterceptor(log)),
+		grpc.ChainStreamInterceptor(streamLoggerInterceptor(cfg.GRPC.Port, cfg.RequestID, requestLog), streamRecoverInterceptor(log)),
+	)
+
+	productv1.RegisterProductServiceServer(server, NewProductService(productUsecase, log))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(productv1.ProductService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	if cfg.GRPC.Reflection {
+		reflection.Register(server)
+	}
+
+	return &defaultServer{server: server, health: healthServer}
+}
+
+func (s *defaultServer) Serve(listener net.Listener) error {
+	return s.server.Serve(listener)
+}
+
+func (s *defaultServer) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
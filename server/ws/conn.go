@@ -0,0 +1,288 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/events"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/fadilahonespot/simple-api/utils/metrics"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	maxMessageSize  = 4096
+	maxIDLength     = 64
+	replyBufferSize = 16
+)
+
+type conn struct {
+	cfg     config.WebSocketConfig
+	ws      *websocket.Conn
+	log     logger.Logger
+	replies chan ServerMessage
+
+	mu            sync.Mutex
+	subscriptions map[string]events.Filter
+	idleSince     time.Time
+}
+
+func newConn(cfg config.WebSocketConfig, ws *websocket.Conn, log logger.Logger) *conn {
+	return &conn{
+		cfg:           cfg,
+		ws:            ws,
+		log:           log,
+		replies:       make(chan ServerMessage, replyBufferSize),
+		subscriptions: make(map[string]events.Filter),
+		idleSince:     time.Now(),
+	}
+}
+
+// serve writes replies, events and pings until the connection ends. Reading
+// happens on a separate goroutine since a connection allows one reader and
+// one writer at a time.
+func (c *conn) serve(ctx context.Context, broker events.Broker) {
+	c.log.Info(ctx, "WebSocket connected", c.ws.RemoteAddr().String())
+
+	sub, _, _ := broker.Subscribe(0, nil)
+	defer sub.Close()
+
+	var readReason string
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		readReason = c.readLoop(ctx)
+	}()
+
+	ping := time.NewTicker(c.cfg.PingInterval)
+	defer ping.Stop()
+	idle := time.NewTimer(c.cfg.IdleTimeout)
+	defer idle.Stop()
+
+	reason := c.writeLoop(sub, ping.C, idle, readDone)
+	c.ws.Close()
+	<-readDone
+	if reason == "" {
+		reason = readReason
+	}
+
+	c.mu.Lock()
+	metrics.WebSocketSubscriptions.Sub(float64(len(c.subscriptions)))
+	c.subscriptions = nil
+	c.mu.Unlock()
+
+	metrics.WebSocketDisconnectsTotal.WithLabelValues(reason).Inc()
+	c.log.Info(ctx, "WebSocket disconnected", reason)
+}
+
+// writeLoop returns the reason the connection was closed, or an empty reason
+// when the reader stopped first.
+func (c *conn) writeLoop(sub events.Subscription, ping <-chan time.Time, idle *time.Timer, readDone <-chan struct{}) string {
+	for {
+		var err error
+		select {
+		case <-readDone:
+			return ""
+		case reply := <-c.replies:
+			err = c.write(reply)
+		case event, ok := <-sub.Events():
+			if !ok {
+				if errors.Is(sub.Err(), events.ErrSlowConsumer) {
+					return c.close(websocket.CloseTryAgainLater, "slow consumer", "slow_consumer")
+				}
+				return c.close(websocket.CloseGoingAway, "server shutting down", "shutdown")
+			}
+			if matched := c.match(event); len(matched) > 0 {
+				err = c.write(ServerMessage{Type: MessageEvent, Subscriptions: matched, EventID: event.ID, Event: &event})
+			}
+		case <-ping:
+			err = c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.cfg.WriteTimeout))
+		case <-idle.C:
+			remaining := c.idleRemaining()
+			if remaining <= 0 {
+				return c.close(websocket.CloseNormalClosure, "idle", "idle")
+			}
+			idle.Reset(remaining)
+		}
+
+		if err != nil {
+			return "write_error"
+		}
+	}
+}
+
+func (c *conn) readLoop(ctx context.Context) string {
+	c.ws.SetReadLimit(maxMessageSize)
+	c.extendReadDeadline()
+	c.ws.SetPongHandler(func(string) error {
+		c.extendReadDeadline()
+		return nil
+	})
+
+	for {
+		var msg ClientMessage
+		err := c.ws.ReadJSON(&msg)
+		if err != nil {
+			var netErr net.Error
+			switch {
+			case websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseAbnormalClosure):
+				return "client"
+			case errors.As(err, &netErr) && netErr.Timeout():
+				return "ping_timeout"
+			case errors.Is(err, net.ErrClosed):
+				// The writer closed the connection and has its own reason.
+				return "closed"
+			case isSyntaxError(err):
+				metrics.WebSocketMessagesTotal.WithLabelValues("in", "invalid").Inc()
+				c.reply(ServerMessage{Type: MessageError, Code: http.StatusBadRequest, Message: "message is not valid JSON"})
+				continue
+			}
+			c.log.Error(ctx, "error reading WebSocket message", err.Error())
+			return "read_error"
+		}
+		c.extendReadDeadline()
+
+		if !c.reply(c.handle(msg)) {
+			return c.close(websocket.CloseTryAgainLater, "slow consumer", "slow_consumer")
+		}
+	}
+}
+
+func (c *conn) handle(msg ClientMessage) ServerMessage {
+	switch msg.Type {
+	case MessageSubscribe, MessageUnsubscribe:
+		metrics.WebSocketMessagesTotal.WithLabelValues("in", msg.Type).Inc()
+	default:
+		metrics.WebSocketMessagesTotal.WithLabelValues("in", "invalid").Inc()
+		return errorMessage(msg.ID, http.StatusBadRequest, "type must be subscribe or unsubscribe")
+	}
+
+	if msg.ID == "" || len(msg.ID) > maxIDLength {
+		return errorMessage(msg.ID, http.StatusBadRequest, "id must be between 1 and 64 characters")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if msg.Type == MessageUnsubscribe {
+		if _, ok := c.subscriptions[msg.ID]; !ok {
+			return errorMessage(msg.ID, http.StatusNotFound, "subscription not found")
+		}
+		delete(c.subscriptions, msg.ID)
+		metrics.WebSocketSubscriptions.Dec()
+		if len(c.subscriptions) == 0 {
+			c.idleSince = time.Now()
+		}
+		return ServerMessage{Type: MessageUnsubscribed, ID: msg.ID}
+	}
+
+	if _, ok := c.subscriptions[msg.ID]; ok {
+		return errorMessage(msg.ID, http.StatusConflict, "subscription already exists")
+	}
+	if len(c.subscriptions) >= c.cfg.MaxSubscriptions {
+		return errorMessage(msg.ID, http.StatusTooManyRequests, "too many subscriptions")
+	}
+
+	filter, err := subscriptionFilter(msg)
+	if err != nil {
+		return errorMessage(msg.ID, http.StatusBadRequest, err.Error())
+	}
+	c.subscriptions[msg.ID] = filter
+	metrics.WebSocketSubscriptions.Inc()
+	return ServerMessage{Type: MessageSubscribed, ID: msg.ID}
+}
+
+func (c *conn) match(event events.Event) (matched []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, filter := range c.subscriptions {
+		if filter == nil || filter(event) {
+			matched = append(matched, id)
+		}
+	}
+	sort.Strings(matched)
+	return
+}
+
+func (c *conn) idleRemaining() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.subscriptions) > 0 {
+		return c.cfg.IdleTimeout
+	}
+	return c.cfg.IdleTimeout - time.Since(c.idleSince)
+}
+
+// reply queues msg for the writer, it reports false when the client does not
+// keep up with its own replies.
+func (c *conn) reply(msg ServerMessage) bool {
+	select {
+	case c.replies <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *conn) write(msg ServerMessage) error {
+	metrics.WebSocketMessagesTotal.WithLabelValues("out", msg.Type).Inc()
+	c.ws.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout))
+	return c.ws.WriteJSON(msg)
+}
+
+func (c *conn) close(code int, text, reason string) string {
+	c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(c.cfg.WriteTimeout))
+	return reason
+}
+
+func (c *conn) extendReadDeadline() {
+	c.ws.SetReadDeadline(time.Now().Add(c.cfg.PingInterval + c.cfg.PongTimeout))
+}
+
+func subscriptionFilter(msg ClientMessage) (events.Filter, error) {
+	productIDs := make(map[string]bool)
+	for _, id := range msg.ProductIDs {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return nil, errors.New("productIds must be UUIDs")
+		}
+		productIDs[parsed.String()] = true
+	}
+
+	var filter events.Filter
+	if msg.Filter != "" {
+		var err error
+		filter, err = events.ParseFilter(msg.Filter)
+		if err != nil {
+			return nil, errors.New("invalid filter: " + err.Error())
+		}
+	}
+
+	if len(productIDs) == 0 {
+		return filter, nil
+	}
+	return func(event events.Event) bool {
+		return productIDs[event.Key] && (filter == nil || filter(event))
+	}, nil
+}
+
+func isSyntaxError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func errorMessage(id string, code int, message string) ServerMessage {
+	return ServerMessage{Type: MessageError, ID: id, Code: code, Message: message}
+}
@@ -0,0 +1,108 @@
+package ws
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/events"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/fadilahonespot/simple-api/utils/metrics"
+	"github.com/gorilla/websocket"
+)
+
+const tokenQueryParam = "access_token"
+
+var (
+	ErrUnauthorized       = errors.New("missing or invalid token")
+	ErrTooManyConnections = errors.New("too many connections")
+)
+
+type Hub interface {
+	// Connect authenticates r, upgrades it and serves the connection until it
+	// is closed. ErrUnauthorized and ErrTooManyConnections are returned before
+	// anything is written to w.
+	Connect(w http.ResponseWriter, r *http.Request) error
+	Connections() int
+}
+
+type defaultHub struct {
+	cfg         config.WebSocketConfig
+	broker      events.Broker
+	log         logger.Logger
+	upgrader    websocket.Upgrader
+	connections int64
+}
+
+func NewHub(cfg config.WebSocketConfig, broker events.Broker, log logger.Logger) Hub {
+	h := &defaultHub{cfg: cfg, broker: broker, log: log}
+	h.upgrader = websocket.Upgrader{CheckOrigin: h.checkOrigin}
+	return h
+}
+
+func (h *defaultHub) Connect(w http.ResponseWriter, r *http.Request) error {
+	if !h.authenticate(r) {
+		metrics.WebSocketRejectedTotal.WithLabelValues("unauthorized").Inc()
+		return ErrUnauthorized
+	}
+
+	if atomic.AddInt64(&h.connections, 1) > int64(h.cfg.MaxConnections) {
+		atomic.AddInt64(&h.connections, -1)
+		metrics.WebSocketRejectedTotal.WithLabelValues("limit").Inc()
+		return ErrTooManyConnections
+	}
+	defer atomic.AddInt64(&h.connections, -1)
+
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		metrics.WebSocketRejectedTotal.WithLabelValues("upgrade").Inc()
+		return err
+	}
+
+	metrics.WebSocketConnections.Inc()
+	defer metrics.WebSocketConnections.Dec()
+
+	newConn(h.cfg, ws, h.log).serve(r.Context(), h.broker)
+	return nil
+}
+
+func (h *defaultHub) Connections() int {
+	return int(atomic.LoadInt64(&h.connections))
+}
+
+func (h *defaultHub) authenticate(r *http.Request) bool {
+	token := r.URL.Query().Get(tokenQueryParam)
+	if scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		token = value
+	}
+	if token == "" {
+		return false
+	}
+
+	for _, allowed := range h.cfg.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(allowed)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *defaultHub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	for _, allowed := range h.cfg.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
@@ -0,0 +1,230 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/events"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const testToken = "secret"
+
+type testProduct struct {
+	Title  string  `json:"title"`
+	Rating float64 `json:"rating"`
+}
+
+func TestMain(m *testing.M) {
+	logger.NewLogger(config.LoggerConfig{})
+	os.Exit(m.Run())
+}
+
+func newTestServer(t *testing.T, cfg config.WebSocketConfig, broker events.Broker) (Hub, string) {
+	cfg.Tokens = []string{testToken}
+	if cfg.MaxConnections == 0 {
+		cfg.MaxConnections = 10
+	}
+	if cfg.MaxSubscriptions == 0 {
+		cfg.MaxSubscriptions = 10
+	}
+	if cfg.PingInterval == 0 {
+		cfg.PingInterval = time.Minute
+	}
+	cfg.PongTimeout = time.Second
+	if cfg.IdleTimeout == 0 {
+		cfg.IdleTimeout = time.Minute
+	}
+	cfg.WriteTimeout = time.Second
+
+	hub := NewHub(cfg, broker, logger.New(logger.NewRecorder(), nil))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := hub.Connect(w, r)
+		switch err {
+		case ErrUnauthorized:
+			w.WriteHeader(http.StatusUnauthorized)
+		case ErrTooManyConnections:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return hub, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	header := http.Header{"Authorization": []string{"Bearer " + testToken}}
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg ClientMessage) ServerMessage {
+	err := conn.WriteJSON(msg)
+	if err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	return receive(t, conn)
+}
+
+func receive(t *testing.T, conn *websocket.Conn) (msg ServerMessage) {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	err := conn.ReadJSON(&msg)
+	if err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	return
+}
+
+func Test_defaultHub_Connect_authentication(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     http.Header
+		query      string
+		wantStatus int
+	}{
+		{name: "missing token", wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", header: http.Header{"Authorization": []string{"Bearer nope"}}, wantStatus: http.StatusUnauthorized},
+		{name: "bearer header", header: http.Header{"Authorization": []string{"Bearer " + testToken}}, wantStatus: http.StatusSwitchingProtocols},
+		{name: "query parameter", query: "?access_token=" + testToken, wantStatus: http.StatusSwitchingProtocols},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, url := newTestServer(t, config.WebSocketConfig{}, events.NewBroker(0, 8))
+
+			conn, resp, _ := websocket.DefaultDialer.Dial(url+tt.query, tt.header)
+			if conn != nil {
+				conn.Close()
+			}
+			if resp == nil || resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Dial() response = %v, want status %d", resp, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_defaultHub_Connect_limit(t *testing.T) {
+	hub, url := newTestServer(t, config.WebSocketConfig{MaxConnections: 1}, events.NewBroker(0, 8))
+
+	dial(t, url)
+	_, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Authorization": []string{"Bearer " + testToken}})
+	if err == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("second Dial() error = %v, want status %d", err, http.StatusServiceUnavailable)
+	}
+	if got := hub.Connections(); got != 1 {
+		t.Errorf("Connections() = %d, want 1", got)
+	}
+}
+
+func Test_defaultHub_subscriptions(t *testing.T) {
+	broker := events.NewBroker(0, 8)
+	_, url := newTestServer(t, config.WebSocketConfig{MaxSubscriptions: 2}, broker)
+	conn := dial(t, url)
+
+	productID := uuid.New().String()
+
+	tests := []struct {
+		name     string
+		msg      ClientMessage
+		wantType string
+		wantCode int
+	}{
+		{name: "unknown type", msg: ClientMessage{Type: "watch", ID: "a"}, wantType: MessageError, wantCode: http.StatusBadRequest},
+		{name: "missing id", msg: ClientMessage{Type: MessageSubscribe}, wantType: MessageError, wantCode: http.StatusBadRequest},
+		{name: "invalid product id", msg: ClientMessage{Type: MessageSubscribe, ID: "a", ProductIDs: []string{"1"}}, wantType: MessageError, wantCode: http.StatusBadRequest},
+		{name: "invalid filter", msg: ClientMessage{Type: MessageSubscribe, ID: "a", Filter: "rating >="}, wantType: MessageError, wantCode: http.StatusBadRequest},
+		{name: "subscribe by product", msg: ClientMessage{Type: MessageSubscribe, ID: "product", ProductIDs: []string{productID}}, wantType: MessageSubscribed},
+		{name: "duplicate id", msg: ClientMessage{Type: MessageSubscribe, ID: "product"}, wantType: MessageError, wantCode: http.StatusConflict},
+		{name: "subscribe by filter", msg: ClientMessage{Type: MessageSubscribe, ID: "rated", Filter: "rating >= 4"}, wantType: MessageSubscribed},
+		{name: "too many subscriptions", msg: ClientMessage{Type: MessageSubscribe, ID: "all"}, wantType: MessageError, wantCode: http.StatusTooManyRequests},
+		{name: "unsubscribe unknown", msg: ClientMessage{Type: MessageUnsubscribe, ID: "all"}, wantType: MessageError, wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := send(t, conn, tt.msg)
+			if got.Type != tt.wantType || got.Code != tt.wantCode {
+				t.Errorf("reply = %+v, want type %s code %d", got, tt.wantType, tt.wantCode)
+			}
+		})
+	}
+
+	broker.Publish(events.ProductUpdated, uuid.New().String(), testProduct{Rating: 1})
+	broker.Publish(events.ProductUpdated, productID, testProduct{Rating: 5})
+	got := receive(t, conn)
+	if got.Type != MessageEvent || got.Event == nil || got.Event.Key != productID {
+		t.Fatalf("event = %+v, want the event of %s", got, productID)
+	}
+	if strings.Join(got.Subscriptions, ",") != "product,rated" {
+		t.Errorf("subscriptions = %v, want [product rated]", got.Subscriptions)
+	}
+
+	if got := send(t, conn, ClientMessage{Type: MessageUnsubscribe, ID: "product"}); got.Type != MessageUnsubscribed {
+		t.Fatalf("unsubscribe reply = %+v", got)
+	}
+	broker.Publish(events.ProductDeleted, productID, nil)
+	broker.Publish(events.ProductCreated, productID, testProduct{Rating: 4})
+	got = receive(t, conn)
+	if got.Event == nil || got.Event.Type != events.ProductCreated || strings.Join(got.Subscriptions, ",") != "rated" {
+		t.Errorf("event = %+v, want product.created for rated", got)
+	}
+}
+
+func Test_defaultHub_closes(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.WebSocketConfig
+		trigger  func(broker events.Broker)
+		wantCode int
+	}{
+		{
+			name:     "idle without subscriptions",
+			cfg:      config.WebSocketConfig{IdleTimeout: 50 * time.Millisecond},
+			wantCode: websocket.CloseNormalClosure,
+		},
+		{
+			name:     "broker shutdown",
+			trigger:  func(broker events.Broker) { broker.Close() },
+			wantCode: websocket.CloseGoingAway,
+		},
+		{
+			name: "slow consumer",
+			trigger: func(broker events.Broker) {
+				for i := 0; i < 3; i++ {
+					broker.Publish(events.ProductCreated, "", nil)
+				}
+			},
+			wantCode: websocket.CloseTryAgainLater,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := events.NewBroker(0, 1)
+			hub, url := newTestServer(t, tt.cfg, broker)
+			conn := dial(t, url)
+
+			if tt.trigger != nil {
+				deadline := time.Now().Add(time.Second)
+				for hub.Connections() == 0 && time.Now().Before(deadline) {
+					time.Sleep(time.Millisecond)
+				}
+				tt.trigger(broker)
+			}
+
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			_, _, err := conn.ReadMessage()
+			if !websocket.IsCloseError(err, tt.wantCode) {
+				t.Fatalf("ReadMessage() error = %v, want close code %d", err, tt.wantCode)
+			}
+		})
+	}
+}
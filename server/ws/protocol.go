@@ -0,0 +1,36 @@
+package ws
+
+import (
+	"github.com/fadilahonespot/simple-api/utils/events"
+)
+
+const (
+	MessageSubscribe    = "subscribe"
+	MessageUnsubscribe  = "unsubscribe"
+	MessageSubscribed   = "subscribed"
+	MessageUnsubscribed = "unsubscribed"
+	MessageEvent        = "event"
+	MessageError        = "error"
+)
+
+// ClientMessage is sent by clients to manage their subscriptions. A
+// subscription matches the events of any of ProductIDs that also match
+// Filter, see events.ParseFilter; without either it matches every event.
+type ClientMessage struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	ProductIDs []string `json:"productIds,omitempty"`
+	Filter     string   `json:"filter,omitempty"`
+}
+
+// ServerMessage acknowledges a ClientMessage, reports an error or delivers an
+// event to the Subscriptions it matched.
+type ServerMessage struct {
+	Type          string        `json:"type"`
+	ID            string        `json:"id,omitempty"`
+	Subscriptions []string      `json:"subscriptions,omitempty"`
+	EventID       uint64        `json:"eventId,omitempty"`
+	Event         *events.Event `json:"event,omitempty"`
+	Code          int           `json:"code,omitempty"`
+	Message       string        `json:"message,omitempty"`
+}
@@ -7,11 +7,19 @@ import (
 	"gorm.io/gorm"
 )
 
+// SectionAudit expands product responses with their audit metadata.
+const SectionAudit = "audit"
+
 type ProductRequest struct {
-	Title       string  `json:"title" validate:"required"`
-	Description string  `json:"description" validate:"required"`
-	Rating      float64 `json:"rating"`
-	Image       string  `json:"image"`
+	Title       string  `json:"title" xml:"title" validate:"required"`
+	Description string  `json:"description" xml:"description" validate:"required"`
+	Rating      float64 `json:"rating" xml:"rating"`
+	Image       string  `json:"image" xml:"image"`
+}
+
+// ProductBatchRequest is the body of POST /products/batch-get.
+type ProductBatchRequest struct {
+	IDs []string `json:"ids" xml:"ids>id" validate:"required,min=1"`
 }
 
 type ProductListResponse struct {
@@ -20,6 +28,22 @@ type ProductListResponse struct {
 	Description string    `json:"description"`
 	Rating      float64   `json:"rating"`
 	Image       string    `json:"image"`
+	Audit       *Audit    `json:"audit,omitempty"`
+}
+
+// ProductBatchItem is the result of one ID of a batch get. Product is null
+// when no product has the ID.
+type ProductBatchItem struct {
+	ID      string               `json:"id"`
+	Found   bool                 `json:"found"`
+	Product *ProductListResponse `json:"product"`
+}
+
+// Audit is the optional section of product responses expanded with
+// ?expand=audit.
+type Audit struct {
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
 type DetailProductResponse struct {
@@ -31,4 +55,18 @@ type DetailProductResponse struct {
 	CreatedAt   time.Time      `json:"createdAt"`
 	UpdatedAt   time.Time      `json:"updatedAt"`
 	DeletedAt   gorm.DeletedAt `json:"deletedAt"`
+	Audit       *Audit         `json:"audit,omitempty"`
+}
+
+// ProductResponseV2 is the product detail of the v2 API, which leaves out
+// deletedAt since deleted products cannot be read.
+type ProductResponseV2 struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Rating      float64   `json:"rating"`
+	Image       string    `json:"image"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	Audit       *Audit    `json:"audit,omitempty"`
 }
@@ -6,6 +6,8 @@ import (
 	context "context"
 
 	dto "github.com/fadilahonespot/simple-api/usecase/dto"
+	fieldset "github.com/fadilahonespot/simple-api/utils/fieldset"
+
 	mock "github.com/stretchr/testify/mock"
 
 	paginate "github.com/fadilahonespot/simple-api/utils/paginate"
@@ -44,23 +46,23 @@ func (_m *ProductUsecase) DeleteProduct(ctx context.Context, productId string) e
 	return r0
 }
 
-// GetDetailProduct provides a mock function with given fields: ctx, productId
-func (_m *ProductUsecase) GetDetailProduct(ctx context.Context, productId string) (dto.DetailProductResponse, error) {
-	ret := _m.Called(ctx, productId)
+// GetDetailProduct provides a mock function with given fields: ctx, productId, fields
+func (_m *ProductUsecase) GetDetailProduct(ctx context.Context, productId string, fields fieldset.Fieldset) (dto.DetailProductResponse, error) {
+	ret := _m.Called(ctx, productId, fields)
 
 	var r0 dto.DetailProductResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (dto.DetailProductResponse, error)); ok {
-		return rf(ctx, productId)
+	if rf, ok := ret.Get(0).(func(context.Context, string, fieldset.Fieldset) (dto.DetailProductResponse, error)); ok {
+		return rf(ctx, productId, fields)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) dto.DetailProductResponse); ok {
-		r0 = rf(ctx, productId)
+	if rf, ok := ret.Get(0).(func(context.Context, string, fieldset.Fieldset) dto.DetailProductResponse); ok {
+		r0 = rf(ctx, productId, fields)
 	} else {
 		r0 = ret.Get(0).(dto.DetailProductResponse)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, productId)
+	if rf, ok := ret.Get(1).(func(context.Context, string, fieldset.Fieldset) error); ok {
+		r1 = rf(ctx, productId, fields)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -68,32 +70,32 @@ func (_m *ProductUsecase) GetDetailProduct(ctx context.Context, productId string
 	return r0, r1
 }
 
-// GetListProduct provides a mock function with given fields: ctx, param
-func (_m *ProductUsecase) GetListProduct(ctx context.Context, param paginate.Pagination) ([]dto.ProductListResponse, int64, error) {
-	ret := _m.Called(ctx, param)
+// GetListProduct provides a mock function with given fields: ctx, param, fields
+func (_m *ProductUsecase) GetListProduct(ctx context.Context, param paginate.Pagination, fields fieldset.Fieldset) ([]dto.ProductListResponse, int64, error) {
+	ret := _m.Called(ctx, param, fields)
 
 	var r0 []dto.ProductListResponse
 	var r1 int64
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, paginate.Pagination) ([]dto.ProductListResponse, int64, error)); ok {
-		return rf(ctx, param)
+	if rf, ok := ret.Get(0).(func(context.Context, paginate.Pagination, fieldset.Fieldset) ([]dto.ProductListResponse, int64, error)); ok {
+		return rf(ctx, param, fields)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, paginate.Pagination) []dto.ProductListResponse); ok {
-		r0 = rf(ctx, param)
+	if rf, ok := ret.Get(0).(func(context.Context, paginate.Pagination, fieldset.Fieldset) []dto.ProductListResponse); ok {
+		r0 = rf(ctx, param, fields)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]dto.ProductListResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, paginate.Pagination) int64); ok {
-		r1 = rf(ctx, param)
+	if rf, ok := ret.Get(1).(func(context.Context, paginate.Pagination, fieldset.Fieldset) int64); ok {
+		r1 = rf(ctx, param, fields)
 	} else {
 		r1 = ret.Get(1).(int64)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, paginate.Pagination) error); ok {
-		r2 = rf(ctx, param)
+	if rf, ok := ret.Get(2).(func(context.Context, paginate.Pagination, fieldset.Fieldset) error); ok {
+		r2 = rf(ctx, param, fields)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -101,6 +103,32 @@ func (_m *ProductUsecase) GetListProduct(ctx context.Context, param paginate.Pag
 	return r0, r1, r2
 }
 
+// GetProductsByIds provides a mock function with given fields: ctx, ids, fields
+func (_m *ProductUsecase) GetProductsByIds(ctx context.Context, ids []string, fields fieldset.Fieldset) ([]dto.ProductBatchItem, error) {
+	ret := _m.Called(ctx, ids, fields)
+
+	var r0 []dto.ProductBatchItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, fieldset.Fieldset) ([]dto.ProductBatchItem, error)); ok {
+		return rf(ctx, ids, fields)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, fieldset.Fieldset) []dto.ProductBatchItem); ok {
+		r0 = rf(ctx, ids, fields)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.ProductBatchItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, fieldset.Fieldset) error); ok {
+		r1 = rf(ctx, ids, fields)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateProduct provides a mock function with given fields: ctx, productId, req
 func (_m *ProductUsecase) UpdateProduct(ctx context.Context, productId string, req dto.ProductRequest) error {
 	ret := _m.Called(ctx, productId, req)
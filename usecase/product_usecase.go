@@ -3,80 +3,114 @@ package usecase
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/fadilahonespot/library/errors"
 	"github.com/fadilahonespot/simple-api/entity"
 	"github.com/fadilahonespot/simple-api/repository"
 	"github.com/fadilahonespot/simple-api/usecase/dto"
+	"github.com/fadilahonespot/simple-api/utils/database"
+	"github.com/fadilahonespot/simple-api/utils/events"
+	"github.com/fadilahonespot/simple-api/utils/fieldset"
 	"github.com/fadilahonespot/simple-api/utils/logger"
 	"github.com/fadilahonespot/simple-api/utils/paginate"
+	"github.com/google/uuid"
 )
 
 type ProductUsecase interface {
 	CreateProduct(ctx context.Context, req dto.ProductRequest) (err error)
-	GetListProduct(ctx context.Context, param paginate.Pagination) (resp []dto.ProductListResponse, count int64, err error)
-	GetDetailProduct(ctx context.Context, productId string) (resp dto.DetailProductResponse, err error)
+	GetListProduct(ctx context.Context, param paginate.Pagination, fields fieldset.Fieldset) (resp []dto.ProductListResponse, count int64, err error)
+	GetDetailProduct(ctx context.Context, productId string, fields fieldset.Fieldset) (resp dto.DetailProductResponse, err error)
+	GetProductsByIds(ctx context.Context, ids []string, fields fieldset.Fieldset) (resp []dto.ProductBatchItem, err error)
 	UpdateProduct(ctx context.Context, productId string, req dto.ProductRequest) (err error)
 	DeleteProduct(ctx context.Context, productId string) (err error)
 }
 
+// MaxBatchIDs is the most products GetProductsByIds reads at once.
+const MaxBatchIDs = 100
+
+// productColumns are the columns read for each field and section of product
+// responses.
+var productColumns = map[string][]string{
+	"id":             {"id"},
+	"title":          {"title"},
+	"description":    {"description"},
+	"rating":         {"rating"},
+	"image":          {"image"},
+	"createdAt":      {"created_at"},
+	"updatedAt":      {"updated_at"},
+	"deletedAt":      {"deleted_at"},
+	dto.SectionAudit: {"created_at", "updated_at"},
+}
+
 type defaultProductUsecase struct {
 	productRepo repository.ProductRepository
+	txManager   repository.TxManager
+	publisher   events.Publisher
+	log         logger.Logger
 }
 
-func NewProductRepository(productRepo repository.ProductRepository) ProductUsecase {
-	return &defaultProductUsecase{productRepo: productRepo}
+func NewProductRepository(productRepo repository.ProductRepository, txManager repository.TxManager, publisher events.Publisher, log logger.Logger) ProductUsecase {
+	return &defaultProductUsecase{productRepo: productRepo, txManager: txManager, publisher: publisher, log: log}
 }
 
 func (s *defaultProductUsecase) CreateProduct(ctx context.Context, req dto.ProductRequest) (err error) {
-	productData, _ := s.productRepo.GetProductByTitle(ctx, req.Title)
-	if productData.Title != "" {
-		logger.Error(ctx, "product is already exist")
-		err = errors.SetError(http.StatusBadRequest, "Product is already exist")
-		return
-	}
+	ctx = database.WithPrimary(ctx)
 	reqProduct := entity.Product{
 		Title:       req.Title,
 		Description: req.Description,
 		Rating:      req.Rating,
 		Image:       req.Image,
 	}
-	err = s.productRepo.CreateProduct(ctx, &reqProduct)
-	if err != nil {
-		logger.Error(ctx, "error creating product", err.Error())
-		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		productData, _ := s.productRepo.GetProductByTitle(ctx, req.Title)
+		if productData != nil && productData.Title != "" {
+			s.log.Error(ctx, "product is already exist")
+			err = errors.SetError(http.StatusBadRequest, "Product is already exist")
+			return
+		}
+		err = s.productRepo.CreateProduct(ctx, &reqProduct)
+		if err == repository.ErrDuplicateProduct {
+			s.log.Error(ctx, "product is already exist")
+			err = errors.SetError(http.StatusBadRequest, "Product is already exist")
+			return
+		}
+		if err != nil {
+			s.log.Error(ctx, "error creating product", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+
 		return
+	})
+	if err != nil {
+		return s.transactionError(ctx, err)
 	}
 
+	s.publisher.Publish(events.ProductCreated, reqProduct.ID.String(), productEvent(reqProduct))
 	return
 }
 
-func (s *defaultProductUsecase) GetListProduct(ctx context.Context, param paginate.Pagination) (resp []dto.ProductListResponse, count int64, err error) {
-	data, count, err := s.productRepo.GetListProduct(ctx, param)
+func (s *defaultProductUsecase) GetListProduct(ctx context.Context, param paginate.Pagination, fields fieldset.Fieldset) (resp []dto.ProductListResponse, count int64, err error) {
+	data, count, err := s.productRepo.GetListProduct(ctx, param, fields.Columns(productColumns)...)
 	if err != nil {
-		logger.Error(ctx, "error getting product list", err.Error())
+		s.log.Error(ctx, "error getting product list", err.Error())
 		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 		return
 	}
 
 	for i := 0; i < len(data); i++ {
-		resp = append(resp, dto.ProductListResponse{
-			ID:          data[i].ID,
-			Title:       data[i].Title,
-			Description: data[i].Description,
-			Rating:      data[i].Rating,
-			Image:       data[i].Image,
-		})
+		resp = append(resp, productListItem(data[i], fields))
 	}
 
 	return
 }
 
-func (s *defaultProductUsecase) GetDetailProduct(ctx context.Context, productId string) (resp dto.DetailProductResponse, err error) {
-	data, err := s.productRepo.GetProductById(ctx, productId)
+func (s *defaultProductUsecase) GetDetailProduct(ctx context.Context, productId string, fields fieldset.Fieldset) (resp dto.DetailProductResponse, err error) {
+	data, err := s.productRepo.GetProductById(ctx, productId, fields.Columns(productColumns)...)
 	if err != nil {
-		logger.Error(ctx, "error getting product", err.Error())
+		s.log.Error(ctx, "error getting product", err.Error())
 		err = errors.SetError(http.StatusNotFound, http.StatusText(http.StatusNotFound))
 		return
 	}
@@ -90,56 +124,195 @@ func (s *defaultProductUsecase) GetDetailProduct(ctx context.Context, productId
 		CreatedAt:   data.CreatedAt,
 		UpdatedAt:   data.UpdatedAt,
 		DeletedAt:   data.DeletedAt,
+		Audit:       audit(*data, fields),
 	}
 
 	return
 }
 
-func (s *defaultProductUsecase) UpdateProduct(ctx context.Context, productId string, req dto.ProductRequest) (err error) {
-	productData, err := s.productRepo.GetProductById(ctx, productId)
-	if err != nil {
-		logger.Error(ctx, "failed to get product: ", err.Error())
-		err = errors.SetError(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+func (s *defaultProductUsecase) GetProductsByIds(ctx context.Context, ids []string, fields fieldset.Fieldset) (resp []dto.ProductBatchItem, err error) {
+	if len(ids) == 0 {
+		s.log.Error(ctx, "no product ids")
+		err = errors.SetError(http.StatusBadRequest, "ids is required")
+		return
+	}
+	if len(ids) > MaxBatchIDs {
+		s.log.Error(ctx, "too many product ids", len(ids))
+		err = errors.SetError(http.StatusBadRequest, "At most "+strconv.Itoa(MaxBatchIDs)+" ids can be fetched at once")
 		return
 	}
 
-	if !strings.EqualFold(productData.Title, req.Title) {
-		productData, _ := s.productRepo.GetProductByTitle(ctx, req.Title)
-		if productData.Title != "" {
-			logger.Error(ctx, "product title is already exist")
-			err = errors.SetError(http.StatusBadRequest, "product is already exist")
-			return
+	// keys are the canonical form of ids, which products are matched by.
+	keys := make([]string, len(ids))
+	var unique, invalid []string
+	for i, id := range ids {
+		uid, parseErr := uuid.Parse(strings.TrimSpace(id))
+		if parseErr != nil {
+			invalid = append(invalid, id)
+			continue
+		}
+		keys[i] = uid.String()
+		if !containsString(unique, keys[i]) {
+			unique = append(unique, keys[i])
 		}
 	}
+	if len(invalid) > 0 {
+		s.log.Error(ctx, "invalid product ids", invalid)
+		err = errors.SetError(http.StatusBadRequest, "Invalid product ids: "+strings.Join(invalid, ", "))
+		return
+	}
 
-	productData.Title = req.Title
-	productData.Description = req.Description
-	productData.Rating = req.Rating
-	productData.Image = req.Image
-	err = s.productRepo.UpdateProduct(ctx, productData)
+	columns := fields.Columns(productColumns)
+	if !fields.Has("id") {
+		columns = append(columns, "id")
+	}
+	data, err := s.productRepo.GetProductsByIds(ctx, unique, columns...)
 	if err != nil {
-		logger.Error(ctx, "failed to update product", err.Error())
+		s.log.Error(ctx, "error getting products by ids", err.Error())
 		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 		return
 	}
 
+	products := make(map[string]entity.Product, len(data))
+	for _, product := range data {
+		products[product.ID.String()] = product
+	}
+	resp = make([]dto.ProductBatchItem, len(ids))
+	for i, id := range ids {
+		resp[i].ID = strings.TrimSpace(id)
+		if product, ok := products[keys[i]]; ok {
+			item := productListItem(product, fields)
+			resp[i].Found = true
+			resp[i].Product = &item
+		}
+	}
+
 	return
 }
 
-func (s *defaultProductUsecase) DeleteProduct(ctx context.Context, productId string) (err error) {
-	_, err = s.productRepo.GetProductById(ctx, productId)
-	if err != nil {
-		logger.Error(ctx, "failed to get product: ", err.Error())
-		err = errors.SetError(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+func (s *defaultProductUsecase) UpdateProduct(ctx context.Context, productId string, req dto.ProductRequest) (err error) {
+	ctx = database.WithPrimary(ctx)
+	var updated entity.Product
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		productData, err := s.productRepo.GetProductById(ctx, productId)
+		if err != nil {
+			s.log.Error(ctx, "failed to get product: ", err.Error())
+			err = errors.SetError(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+			return
+		}
+
+		if !strings.EqualFold(productData.Title, req.Title) {
+			productData, _ := s.productRepo.GetProductByTitle(ctx, req.Title)
+			if productData != nil && productData.Title != "" {
+				s.log.Error(ctx, "product title is already exist")
+				err = errors.SetError(http.StatusBadRequest, "product is already exist")
+				return
+			}
+		}
+
+		productData.Title = req.Title
+		productData.Description = req.Description
+		productData.Rating = req.Rating
+		productData.Image = req.Image
+		err = s.productRepo.UpdateProduct(ctx, productData)
+		if err == repository.ErrDuplicateProduct {
+			s.log.Error(ctx, "product title is already exist")
+			err = errors.SetError(http.StatusBadRequest, "product is already exist")
+			return
+		}
+		if err != nil {
+			s.log.Error(ctx, "failed to update product", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+
+		updated = *productData
 		return
+	})
+	if err != nil {
+		return s.transactionError(ctx, err)
 	}
 
-	err = s.productRepo.DeleteProduct(ctx, productId)
-	if err != nil {
-		logger.Error(ctx, "failed to delete product: ", err.Error())
-		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	s.publisher.Publish(events.ProductUpdated, productId, productEvent(updated))
+	return
+}
+
+func (s *defaultProductUsecase) DeleteProduct(ctx context.Context, productId string) (err error) {
+	ctx = database.WithPrimary(ctx)
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		_, err = s.productRepo.GetProductById(ctx, productId)
+		if err != nil {
+			s.log.Error(ctx, "failed to get product: ", err.Error())
+			err = errors.SetError(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+			return
+		}
+
+		err = s.productRepo.DeleteProduct(ctx, productId)
+		if err != nil {
+			s.log.Error(ctx, "failed to delete product: ", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+
 		return
+	})
+	if err != nil {
+		return s.transactionError(ctx, err)
 	}
 
+	s.publisher.Publish(events.ProductDeleted, productId, nil)
 	return
 }
+
+func productListItem(product entity.Product, fields fieldset.Fieldset) dto.ProductListResponse {
+	return dto.ProductListResponse{
+		ID:          product.ID,
+		Title:       product.Title,
+		Description: product.Description,
+		Rating:      product.Rating,
+		Image:       product.Image,
+		Audit:       audit(product, fields),
+	}
+}
+
+func audit(product entity.Product, fields fieldset.Fieldset) *dto.Audit {
+	if !fields.Expands(dto.SectionAudit) {
+		return nil
+	}
+	return &dto.Audit{CreatedAt: product.CreatedAt, UpdatedAt: product.UpdatedAt}
+}
+
+func productEvent(product entity.Product) dto.DetailProductResponse {
+	return dto.DetailProductResponse{
+		ID:          product.ID,
+		Title:       product.Title,
+		Description: product.Description,
+		Rating:      product.Rating,
+		Image:       product.Image,
+		CreatedAt:   product.CreatedAt,
+		UpdatedAt:   product.UpdatedAt,
+		DeletedAt:   product.DeletedAt,
+	}
+}
+
+func (s *defaultProductUsecase) transactionError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*errors.ApplicationError); ok {
+		return err
+	}
+
+	s.log.Error(ctx, "transaction failed", err.Error())
+	return errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/usecase/dto"
+	"github.com/fadilahonespot/simple-api/utils/fieldset"
+	"github.com/fadilahonespot/simple-api/utils/metrics"
+	"github.com/fadilahonespot/simple-api/utils/paginate"
+	"github.com/fadilahonespot/simple-api/utils/tracing"
+)
+
+type instrumentedProductUsecase struct {
+	next ProductUsecase
+}
+
+func NewInstrumentedProductUsecase(next ProductUsecase) ProductUsecase {
+	return &instrumentedProductUsecase{next: next}
+}
+
+func (s *instrumentedProductUsecase) CreateProduct(ctx context.Context, req dto.ProductRequest) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "ProductUsecase.CreateProduct")
+	defer func(start time.Time) {
+		tracing.EndSpan(span, err)
+		metrics.ObserveUsecase("CreateProduct", start, err)
+		if err == nil {
+			metrics.ProductsCreatedTotal.Inc()
+		}
+	}(time.Now())
+
+	return s.next.CreateProduct(ctx, req)
+}
+
+func (s *instrumentedProductUsecase) GetListProduct(ctx context.Context, param paginate.Pagination, fields fieldset.Fieldset) (resp []dto.ProductListResponse, count int64, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "ProductUsecase.GetListProduct")
+	defer func(start time.Time) {
+		tracing.EndSpan(span, err)
+		metrics.ObserveUsecase("GetListProduct", start, err)
+	}(time.Now())
+
+	return s.next.GetListProduct(ctx, param, fields)
+}
+
+func (s *instrumentedProductUsecase) GetDetailProduct(ctx context.Context, productId string, fields fieldset.Fieldset) (resp dto.DetailProductResponse, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "ProductUsecase.GetDetailProduct")
+	defer func(start time.Time) {
+		tracing.EndSpan(span, err)
+		metrics.ObserveUsecase("GetDetailProduct", start, err)
+	}(time.Now())
+
+	return s.next.GetDetailProduct(ctx, productId, fields)
+}
+
+func (s *instrumentedProductUsecase) GetProductsByIds(ctx context.Context, ids []string, fields fieldset.Fieldset) (resp []dto.ProductBatchItem, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "ProductUsecase.GetProductsByIds")
+	defer func(start time.Time) {
+		tracing.EndSpan(span, err)
+		metrics.ObserveUsecase("GetProductsByIds", start, err)
+	}(time.Now())
+
+	return s.next.GetProductsByIds(ctx, ids, fields)
+}
+
+func (s *instrumentedProductUsecase) UpdateProduct(ctx context.Context, productId string, req dto.ProductRequest) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "ProductUsecase.UpdateProduct")
+	defer func(start time.Time) {
+		tracing.EndSpan(span, err)
+		metrics.ObserveUsecase("UpdateProduct", start, err)
+		if err == nil {
+			metrics.ProductsUpdatedTotal.Inc()
+		}
+	}(time.Now())
+
+	return s.next.UpdateProduct(ctx, productId, req)
+}
+
+func (s *instrumentedProductUsecase) DeleteProduct(ctx context.Context, productId string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "ProductUsecase.DeleteProduct")
+	defer func(start time.Time) {
+		tracing.EndSpan(span, err)
+		metrics.ObserveUsecase("DeleteProduct", start, err)
+		if err == nil {
+			metrics.ProductsDeletedTotal.Inc()
+		}
+	}(time.Now())
+
+	return s.next.DeleteProduct(ctx, productId)
+}
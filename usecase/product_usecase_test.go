@@ -3,22 +3,28 @@ package usecase
 import (
 	"context"
 	"errors"
+	"net/http"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	libErrors "github.com/fadilahonespot/library/errors"
 	"github.com/fadilahonespot/simple-api/entity"
+	"github.com/fadilahonespot/simple-api/repository"
 	"github.com/fadilahonespot/simple-api/repository/mocks"
 	"github.com/fadilahonespot/simple-api/usecase/dto"
+	"github.com/fadilahonespot/simple-api/utils/events"
+	"github.com/fadilahonespot/simple-api/utils/fieldset"
 	"github.com/fadilahonespot/simple-api/utils/logger"
 	"github.com/fadilahonespot/simple-api/utils/paginate"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func Test_defaultProductUsecase_CreateProduct(t *testing.T) {
 	ctx := context.TODO()
-	logger.NewLogger()
-
 	type args struct {
 		ctx context.Context
 		req dto.ProductRequest
@@ -60,6 +66,18 @@ func Test_defaultProductUsecase_CreateProduct(t *testing.T) {
 			createProductErr: errors.New("create product error"),
 			wantErr:          true,
 		},
+		{
+			name: "create product when title lookup finds nothing",
+			args: args{
+				ctx: ctx,
+				req: dto.ProductRequest{
+					Title:       "Mie indomi Rasa ayam Bawang",
+					Description: "Taburan ayam gurih nikmat di setiap kemasan",
+				},
+			},
+			getProductErr: gorm.ErrRecordNotFound,
+			wantErr:       false,
+		},
 		{
 			name: "create product success",
 			args: args{
@@ -81,7 +99,7 @@ func Test_defaultProductUsecase_CreateProduct(t *testing.T) {
 			productRepo.On("GetProductByTitle", mock.Anything, mock.Anything).Return(tt.getProductResp, tt.getProductErr).Once()
 			productRepo.On("CreateProduct", mock.Anything, mock.Anything).Return(tt.createProductErr).Once()
 
-			svc := NewProductRepository(productRepo)
+			svc := NewProductRepository(productRepo, newPassthroughTxManager(), events.NewBroker(0, 1), logger.New(logger.NewRecorder(), nil))
 			if err := svc.CreateProduct(tt.args.ctx, tt.args.req); (err != nil) != tt.wantErr {
 				t.Errorf("defaultProductUsecase.CreateProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -91,17 +109,19 @@ func Test_defaultProductUsecase_CreateProduct(t *testing.T) {
 
 func Test_defaultProductUsecase_GetListProduct(t *testing.T) {
 	ctx := context.TODO()
-	logger.NewLogger()
-
 	uid, _ := uuid.Parse("a1b91cb9-c4a5-408f-ad28-5f32e197d954")
 
+	createdAt := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+
 	type args struct {
-		ctx   context.Context
-		param paginate.Pagination
+		ctx    context.Context
+		param  paginate.Pagination
+		fields fieldset.Fieldset
 	}
 	tests := []struct {
 		name        string
 		args        args
+		wantColumns []interface{}
 		listProduct []entity.Product
 		listCount   int64
 		listErr     error
@@ -152,14 +172,43 @@ func Test_defaultProductUsecase_GetListProduct(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "sparse fieldset with audit",
+			args: args{
+				ctx: ctx,
+				param: paginate.Pagination{
+					Page:  1,
+					Limit: 10,
+				},
+				fields: fieldset.Fieldset{Fields: []string{"id", "title"}, Expand: []string{dto.SectionAudit}},
+			},
+			wantColumns: []interface{}{"id", "title", "created_at", "updated_at"},
+			listProduct: []entity.Product{
+				{
+					ID:        uid,
+					Title:     "Mie indomi Rasa ayam Bawang",
+					CreatedAt: createdAt,
+					UpdatedAt: createdAt,
+				},
+			},
+			listCount: 1,
+			wantCount: 1,
+			wantResp: []dto.ProductListResponse{
+				{
+					ID:    uid,
+					Title: "Mie indomi Rasa ayam Bawang",
+					Audit: &dto.Audit{CreatedAt: createdAt, UpdatedAt: createdAt},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			productRepo := new(mocks.ProductRepository)
-			productRepo.On("GetListProduct", mock.Anything, mock.Anything).Return(tt.listProduct, tt.listCount, tt.listErr).Once()
+			productRepo.On("GetListProduct", append([]interface{}{mock.Anything, mock.Anything}, tt.wantColumns...)...).Return(tt.listProduct, tt.listCount, tt.listErr).Once()
 
-			svc := NewProductRepository(productRepo)
-			gotResp, gotCount, err := svc.GetListProduct(tt.args.ctx, tt.args.param)
+			svc := NewProductRepository(productRepo, newPassthroughTxManager(), events.NewBroker(0, 1), logger.New(logger.NewRecorder(), nil))
+			gotResp, gotCount, err := svc.GetListProduct(tt.args.ctx, tt.args.param, tt.args.fields)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("defaultProductUsecase.GetListProduct() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -176,7 +225,6 @@ func Test_defaultProductUsecase_GetListProduct(t *testing.T) {
 
 func Test_defaultProductUsecase_GetDetailProduct(t *testing.T) {
 	ctx := context.TODO()
-	logger.NewLogger()
 	uidStr := "a1b91cb9-c4a5-408f-ad28-5f32e197d954"
 	uid, _ := uuid.Parse(uidStr)
 
@@ -229,8 +277,8 @@ func Test_defaultProductUsecase_GetDetailProduct(t *testing.T) {
 			productRepo := new(mocks.ProductRepository)
 			productRepo.On("GetProductById", mock.Anything, mock.Anything).Return(tt.getProductResp, tt.getProductErr).Once()
 
-			svc := NewProductRepository(productRepo)
-			gotResp, err := svc.GetDetailProduct(tt.args.ctx, tt.args.productId)
+			svc := NewProductRepository(productRepo, newPassthroughTxManager(), events.NewBroker(0, 1), logger.New(logger.NewRecorder(), nil))
+			gotResp, err := svc.GetDetailProduct(tt.args.ctx, tt.args.productId, fieldset.Fieldset{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("defaultProductUsecase.GetDetailProduct() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -242,9 +290,99 @@ func Test_defaultProductUsecase_GetDetailProduct(t *testing.T) {
 	}
 }
 
+func Test_defaultProductUsecase_GetProductsByIds(t *testing.T) {
+	ctx := context.TODO()
+	first, _ := uuid.Parse("a1b91cb9-c4a5-408f-ad28-5f32e197d954")
+	second, _ := uuid.Parse("22c8e385-6d60-4ddb-87b2-3fb543d43177")
+	missing := "b34e8eac-ac43-4163-b9ad-49f15644b4fa"
+
+	tooMany := make([]string, MaxBatchIDs+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.NewString()
+	}
+
+	tests := []struct {
+		name        string
+		ids         []string
+		fields      fieldset.Fieldset
+		wantIds     []interface{}
+		wantColumns []interface{}
+		products    []entity.Product
+		productsErr error
+		wantResp    []dto.ProductBatchItem
+		wantErr     bool
+	}{
+		{
+			name:    "no ids",
+			wantErr: true,
+		},
+		{
+			name:    "too many ids",
+			ids:     tooMany,
+			wantErr: true,
+		},
+		{
+			name:    "invalid ids",
+			ids:     []string{first.String(), "1", "abc"},
+			wantErr: true,
+		},
+		{
+			name:        "failed to get products",
+			ids:         []string{first.String()},
+			wantIds:     []interface{}{[]string{first.String()}},
+			productsErr: errors.New("failed to get products"),
+			wantErr:     true,
+		},
+		{
+			name:    "products in the order of ids",
+			ids:     []string{second.String(), missing, " " + strings.ToUpper(first.String()), second.String()},
+			wantIds: []interface{}{[]string{second.String(), missing, first.String()}},
+			products: []entity.Product{
+				{ID: first, Title: "Mie indomi Rasa ayam Bawang"},
+				{ID: second, Title: "Mie indomi Rasa ayam Soto"},
+			},
+			wantResp: []dto.ProductBatchItem{
+				{ID: second.String(), Found: true, Product: &dto.ProductListResponse{ID: second, Title: "Mie indomi Rasa ayam Soto"}},
+				{ID: missing},
+				{ID: strings.ToUpper(first.String()), Found: true, Product: &dto.ProductListResponse{ID: first, Title: "Mie indomi Rasa ayam Bawang"}},
+				{ID: second.String(), Found: true, Product: &dto.ProductListResponse{ID: second, Title: "Mie indomi Rasa ayam Soto"}},
+			},
+		},
+		{
+			name:        "sparse fieldset reads the id",
+			ids:         []string{first.String()},
+			fields:      fieldset.Fieldset{Fields: []string{"title"}},
+			wantIds:     []interface{}{[]string{first.String()}},
+			wantColumns: []interface{}{"title", "id"},
+			products:    []entity.Product{{ID: first, Title: "Mie indomi Rasa ayam Bawang"}},
+			wantResp: []dto.ProductBatchItem{
+				{ID: first.String(), Found: true, Product: &dto.ProductListResponse{ID: first, Title: "Mie indomi Rasa ayam Bawang"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productRepo := new(mocks.ProductRepository)
+			if tt.wantIds != nil {
+				args := append([]interface{}{mock.Anything}, tt.wantIds...)
+				productRepo.On("GetProductsByIds", append(args, tt.wantColumns...)...).Return(tt.products, tt.productsErr).Once()
+			}
+
+			svc := NewProductRepository(productRepo, newPassthroughTxManager(), events.NewBroker(0, 1), logger.New(logger.NewRecorder(), nil))
+			gotResp, err := svc.GetProductsByIds(ctx, tt.ids, tt.fields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("defaultProductUsecase.GetProductsByIds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultProductUsecase.GetProductsByIds() = %+v, want %+v", gotResp, tt.wantResp)
+			}
+			productRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func Test_defaultProductUsecase_UpdateProduct(t *testing.T) {
 	ctx := context.TODO()
-	logger.NewLogger()
 	uidStr := "a1b91cb9-c4a5-408f-ad28-5f32e197d954"
 	uid, _ := uuid.Parse(uidStr)
 
@@ -335,7 +473,7 @@ func Test_defaultProductUsecase_UpdateProduct(t *testing.T) {
 			productRepo.On("GetProductByTitle", mock.Anything, mock.Anything).Return(tt.getProductTitleResp, tt.getProductTitleErr).Once()
 			productRepo.On("UpdateProduct", mock.Anything, mock.Anything).Return(tt.updateProductErr).Once()
 
-			svc := NewProductRepository(productRepo)
+			svc := NewProductRepository(productRepo, newPassthroughTxManager(), events.NewBroker(0, 1), logger.New(logger.NewRecorder(), nil))
 			if err := svc.UpdateProduct(tt.args.ctx, tt.args.productId, tt.args.req); (err != nil) != tt.wantErr {
 				t.Errorf("defaultProductUsecase.UpdateProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -345,7 +483,6 @@ func Test_defaultProductUsecase_UpdateProduct(t *testing.T) {
 
 func Test_defaultProductUsecase_DeleteProduct(t *testing.T) {
 	ctx := context.TODO()
-	logger.NewLogger()
 	uidStr := "a1b91cb9-c4a5-408f-ad28-5f32e197d954"
 	uid, _ := uuid.Parse(uidStr)
 
@@ -408,10 +545,110 @@ func Test_defaultProductUsecase_DeleteProduct(t *testing.T) {
 			productRepo.On("GetProductById", mock.Anything, mock.Anything).Return(tt.getProductResp, tt.getProductErr).Once()
 			productRepo.On("DeleteProduct", mock.Anything, mock.Anything).Return(tt.deleteProductErr).Once()
 
-			svc := NewProductRepository(productRepo)
+			svc := NewProductRepository(productRepo, newPassthroughTxManager(), events.NewBroker(0, 1), logger.New(logger.NewRecorder(), nil))
 			if err := svc.DeleteProduct(tt.args.ctx, tt.args.productId); (err != nil) != tt.wantErr {
 				t.Errorf("defaultProductUsecase.DeleteProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func newPassthroughTxManager() *mocks.TxManager {
+	txManager := new(mocks.TxManager)
+	txManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	return txManager
+}
+
+func Test_defaultProductUsecase_CreateProduct_transaction(t *testing.T) {
+	ctx := context.TODO()
+	req := dto.ProductRequest{
+		Title:       "Mie indomi Rasa ayam Bawang",
+		Description: "Taburan ayam gurih nikmat di setiap kemasan",
+	}
+
+	tests := []struct {
+		name             string
+		createProductErr error
+		commitErr        error
+		wantCode         int
+	}{
+		{
+			name:             "concurrent create hits unique index",
+			createProductErr: repository.ErrDuplicateProduct,
+			wantCode:         http.StatusBadRequest,
+		},
+		{
+			name:      "commit failed",
+			commitErr: errors.New("commit failed"),
+			wantCode:  http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productRepo := new(mocks.ProductRepository)
+			productRepo.On("GetProductByTitle", mock.Anything, mock.Anything).Return(&entity.Product{}, nil).Once()
+			productRepo.On("CreateProduct", mock.Anything, mock.Anything).Return(tt.createProductErr).Once()
+
+			txManager := new(mocks.TxManager)
+			txManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+				if err := fn(ctx); err != nil {
+					return err
+				}
+				return tt.commitErr
+			}).Once()
+
+			broker := events.NewBroker(0, 1)
+			sub, _, _ := broker.Subscribe(0, nil)
+
+			svc := NewProductRepository(productRepo, txManager, broker, logger.New(logger.NewRecorder(), nil))
+			err := svc.CreateProduct(ctx, req)
+			if code := libErrors.GetErrorCode(err); code != tt.wantCode {
+				t.Errorf("defaultProductUsecase.CreateProduct() error code = %v, want %v", code, tt.wantCode)
+			}
+			if len(sub.Events()) != 0 {
+				t.Errorf("defaultProductUsecase.CreateProduct() published an event for a failed transaction")
+			}
+			txManager.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_defaultProductUsecase_publishesEvents(t *testing.T) {
+	ctx := context.TODO()
+	productId := "a1b91cb9-c4a5-408f-ad28-5f32e197d954"
+	req := dto.ProductRequest{Title: "Mie indomi", Description: "Taburan ayam gurih"}
+
+	productRepo := new(mocks.ProductRepository)
+	productRepo.On("GetProductByTitle", mock.Anything, mock.Anything).Return(&entity.Product{}, nil)
+	productRepo.On("CreateProduct", mock.Anything, mock.Anything).Return(nil).Once()
+	productRepo.On("GetProductById", mock.Anything, productId).Return(&entity.Product{Title: "Mie"}, nil)
+	productRepo.On("UpdateProduct", mock.Anything, mock.Anything).Return(nil).Once()
+	productRepo.On("DeleteProduct", mock.Anything, productId).Return(nil).Once()
+
+	broker := events.NewBroker(0, 10)
+	sub, _, _ := broker.Subscribe(0, nil)
+	svc := NewProductRepository(productRepo, newPassthroughTxManager(), broker, logger.New(logger.NewRecorder(), nil))
+
+	if err := svc.CreateProduct(ctx, req); err != nil {
+		t.Fatalf("defaultProductUsecase.CreateProduct() error = %v", err)
+	}
+	if err := svc.UpdateProduct(ctx, productId, req); err != nil {
+		t.Fatalf("defaultProductUsecase.UpdateProduct() error = %v", err)
+	}
+	if err := svc.DeleteProduct(ctx, productId); err != nil {
+		t.Fatalf("defaultProductUsecase.DeleteProduct() error = %v", err)
+	}
+
+	want := []string{events.ProductCreated, events.ProductUpdated, events.ProductDeleted}
+	for _, eventType := range want {
+		event := <-sub.Events()
+		if event.Type != eventType {
+			t.Errorf("published event = %v, want %v", event.Type, eventType)
+		}
+	}
+	if len(sub.Events()) != 0 {
+		t.Errorf("published %v unexpected events", len(sub.Events()))
+	}
+}
@@ -0,0 +1,183 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/fadilahonespot/simple-api/utils/metrics"
+)
+
+// Reloader serves the certificate and client CAs of a TLSConfig, reloading
+// them when the files change so renewed certificates need no restart.
+type Reloader interface {
+	// TLSConfig returns the server configuration, every handshake uses the
+	// files loaded last.
+	TLSConfig() *tls.Config
+	// Reload loads the files again if any of them changed.
+	Reload() error
+	// Watch reloads every ReloadInterval until ctx is done.
+	Watch(ctx context.Context)
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+type defaultReloader struct {
+	cfg  config.TLSConfig
+	base *tls.Config
+	log  logger.Logger
+
+	mu      sync.RWMutex
+	current *tls.Config
+	stamps  map[string]fileStamp
+}
+
+// NewReloader loads the files of cfg, which must have TLS enabled.
+func NewReloader(cfg config.TLSConfig, log logger.Logger) (Reloader, error) {
+	if cfg.ClientAuth != "none" && cfg.ClientCAFile == "" {
+		return nil, errors.New("TLS_CLIENT_AUTH requires TLS_CLIENT_CA_FILE")
+	}
+
+	base := &tls.Config{MinVersion: tls.VersionTLS12, NextProtos: []string{"http/1.1"}}
+	if cfg.MinVersion == "1.3" {
+		base.MinVersion = tls.VersionTLS13
+	}
+	if cfg.HTTP2 {
+		base.NextProtos = []string{"h2", "http/1.1"}
+	}
+	switch cfg.ClientAuth {
+	case "optional":
+		base.ClientAuth = tls.VerifyClientCertIfGiven
+	case "require":
+		base.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	r := &defaultReloader{cfg: cfg, base: base, log: log}
+	err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *defaultReloader) TLSConfig() *tls.Config {
+	server := r.base.Clone()
+	server.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		return r.current, nil
+	}
+	return server
+}
+
+func (r *defaultReloader) Reload() error {
+	if !r.changed() {
+		return nil
+	}
+
+	err := r.load()
+	if err != nil {
+		metrics.TLSReloadsTotal.WithLabelValues("error").Inc()
+		return err
+	}
+	metrics.TLSReloadsTotal.WithLabelValues("success").Inc()
+	return nil
+}
+
+func (r *defaultReloader) Watch(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := r.Reload()
+			if err != nil {
+				// The previous certificate keeps being served.
+				r.log.Error(ctx, "error reloading TLS certificate", err.Error())
+			}
+		}
+	}
+}
+
+func (r *defaultReloader) load() error {
+	stamps := make(map[string]fileStamp)
+	for _, path := range r.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	current := r.base.Clone()
+	current.Certificates = []tls.Certificate{cert}
+	if r.cfg.ClientCAFile != "" {
+		content, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(content) {
+			return fmt.Errorf("no certificate found in %s", r.cfg.ClientCAFile)
+		}
+		current.ClientCAs = pool
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return err
+	}
+	metrics.TLSCertificateExpiry.Set(float64(leaf.NotAfter.Unix()))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current = current
+	r.stamps = stamps
+	r.log.Info(context.Background(), "Loaded TLS certificate", leaf.Subject.String(), leaf.NotAfter.Format(time.RFC3339))
+	return nil
+}
+
+func (r *defaultReloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, path := range r.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			// The file is being replaced, try again on the next tick.
+			return false
+		}
+		if stamp := r.stamps[path]; !stamp.modTime.Equal(info.ModTime()) || stamp.size != info.Size() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *defaultReloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
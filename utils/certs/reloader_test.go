@@ -0,0 +1,215 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+	tls  tls.Certificate
+}
+
+func newTestCert(t *testing.T, commonName string, serial int64, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, pem: certPEM, tls: pair}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string, modTime time.Time) {
+	keyDER, _ := x509.MarshalECPrivateKey(c.key)
+	os.WriteFile(certFile, c.pem, 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	os.Chtimes(certFile, modTime, modTime)
+	os.Chtimes(keyFile, modTime, modTime)
+}
+
+func servedSerial(t *testing.T, r Reloader) int64 {
+	server, err := r.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(server.Certificates[0].Certificate[0])
+	return leaf.SerialNumber.Int64()
+}
+
+func Test_defaultReloader_Reload(t *testing.T) {
+	ca := newTestCert(t, "test-ca", 1, nil)
+
+	tests := []struct {
+		name       string
+		replace    func(t *testing.T, certFile, keyFile string)
+		wantErr    bool
+		wantSerial int64
+	}{
+		{
+			name:       "files unchanged",
+			replace:    func(t *testing.T, certFile, keyFile string) {},
+			wantSerial: 2,
+		},
+		{
+			name: "renewed certificate",
+			replace: func(t *testing.T, certFile, keyFile string) {
+				newTestCert(t, "localhost", 3, ca).write(t, certFile, keyFile, time.Now().Add(time.Minute))
+			},
+			wantSerial: 3,
+		},
+		{
+			name: "invalid certificate keeps the previous one",
+			replace: func(t *testing.T, certFile, keyFile string) {
+				os.WriteFile(certFile, []byte("not a certificate"), 0o600)
+			},
+			wantErr:    true,
+			wantSerial: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+			newTestCert(t, "localhost", 2, ca).write(t, certFile, keyFile, time.Now())
+
+			r, err := NewReloader(config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: "none", ReloadInterval: time.Second}, logger.New(logger.NewRecorder(), nil))
+			if err != nil {
+				t.Fatalf("NewReloader() error = %v", err)
+			}
+
+			tt.replace(t, certFile, keyFile)
+			if err := r.Reload(); (err != nil) != tt.wantErr {
+				t.Errorf("Reloader.Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := servedSerial(t, r); got != tt.wantSerial {
+				t.Errorf("served certificate serial = %v, want %v", got, tt.wantSerial)
+			}
+		})
+	}
+}
+
+func TestReloader_TLSConfig(t *testing.T) {
+	ca := newTestCert(t, "test-ca", 1, nil)
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	newTestCert(t, "localhost", 2, ca).write(t, certFile, keyFile, time.Now())
+	os.WriteFile(caFile, ca.pem, 0o600)
+
+	r, err := NewReloader(config.TLSConfig{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		HTTP2:          true,
+		ClientAuth:     "require",
+		ClientCAFile:   caFile,
+		ReloadInterval: time.Second,
+	}, logger.New(logger.NewRecorder(), nil))
+	if err != nil {
+		t.Fatalf("NewReloader() error = %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = r.TLSConfig()
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	tests := []struct {
+		name       string
+		clientCert *testCert
+		wantErr    bool
+	}{
+		{name: "client certificate signed by the CA", clientCert: newTestCert(t, "billing-service", 4, ca)},
+		{name: "no client certificate", wantErr: true},
+		{name: "client certificate of another CA", clientCert: newTestCert(t, "intruder", 5, newTestCert(t, "other-ca", 6, nil)), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientTLS := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+			if tt.clientCert != nil {
+				clientTLS.Certificates = []tls.Certificate{tt.clientCert.tls}
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS, ForceAttemptHTTP2: true}}
+
+			resp, err := client.Get(server.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GET error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+
+			if resp.ProtoMajor != 2 {
+				t.Errorf("GET protocol = %v, want HTTP/2", resp.Proto)
+			}
+		})
+	}
+}
+
+func TestNewReloader(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.TLSConfig
+	}{
+		{name: "missing files", cfg: config.TLSConfig{CertFile: "missing.crt", KeyFile: "missing.key", ClientAuth: "none"}},
+		{name: "client auth without CA", cfg: config.TLSConfig{CertFile: "missing.crt", KeyFile: "missing.key", ClientAuth: "require"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewReloader(tt.cfg, logger.New(logger.NewRecorder(), nil)); err == nil {
+				t.Errorf("NewReloader() error = nil, want an error")
+			}
+		})
+	}
+}
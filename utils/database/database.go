@@ -1,35 +1,157 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"os"
+	"net"
+	"time"
 
+	"github.com/fadilahonespot/simple-api/config"
 	"github.com/fadilahonespot/simple-api/entity"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+	"github.com/spf13/cast"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
-func InitDB() *gorm.DB {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True&loc=Local",
-		os.Getenv("DB_USERNAME"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"),
-	)
+// Replica is a read replica from DatabaseConfig.ReplicaHosts, kept apart
+// from the resolver so each one can be checked for readiness.
+type Replica struct {
+	Host string
+	DB   *sql.DB
+}
+
+func InitDB(cfg config.DatabaseConfig, log logger.Logger) (*gorm.DB, []Replica) {
+	backoff := cfg.ConnectBackoff
+	for attempt := 0; ; attempt++ {
+		DB, replicas, err := openDB(cfg)
+		if err == nil {
+			if cfg.Migration {
+				err = DB.AutoMigrate(&entity.Product{}, &entity.IdempotencyKey{})
+				if err != nil {
+					log.Error(context.Background(), "failed to migrate database", err.Error())
+					panic(err)
+				}
+			}
+
+			return DB, replicas
+		}
+
+		if attempt >= cfg.ConnectRetries {
+			panic(err)
+		}
+
+		log.Error(context.Background(), "failed to connect database, retrying", err.Error(), fmt.Sprintf("attempt %d, next retry in %v", attempt+1, backoff))
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > cfg.ConnectMaxBackoff {
+			backoff = cfg.ConnectMaxBackoff
+		}
+	}
+}
 
-	DB, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+func openDB(cfg config.DatabaseConfig) (DB *gorm.DB, replicas []Replica, err error) {
+	DB, err = gorm.Open(mysql.Open(dsn(cfg, cfg.Host, cfg.Port)), &gorm.Config{TranslateError: true})
 	if err != nil {
-		panic(err)
+		return
 	}
 
-	if os.Getenv("DB_DEBUG") == "true" {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if len(cfg.ReplicaHosts) > 0 {
+		var dialectors []gorm.Dialector
+		for _, replica := range cfg.ReplicaHosts {
+			host, port, splitErr := net.SplitHostPort(replica)
+			if splitErr != nil {
+				closeAll(sqlDB, replicas)
+				return nil, nil, fmt.Errorf("invalid replica host %q: %w", replica, splitErr)
+			}
+
+			replicaDSN := dsn(cfg, host, cast.ToInt(port))
+			replicaDB, openErr := sql.Open("mysql", replicaDSN)
+			if openErr != nil {
+				closeAll(sqlDB, replicas)
+				return nil, nil, openErr
+			}
+			replicas = append(replicas, Replica{Host: replica, DB: replicaDB})
+			dialectors = append(dialectors, mysql.New(mysql.Config{DSN: replicaDSN, Conn: replicaDB}))
+		}
+
+		err = DB.Use(dbresolver.Register(dbresolver.Config{
+			Replicas: dialectors,
+			Policy:   dbresolver.RandomPolicy{},
+		}).
+			SetMaxOpenConns(cfg.MaxOpenConns).
+			SetMaxIdleConns(cfg.MaxIdleConns).
+			SetConnMaxLifetime(cfg.ConnMaxLifetime).
+			SetConnMaxIdleTime(cfg.ConnMaxIdleTime))
+		if err != nil {
+			closeAll(sqlDB, replicas)
+			return nil, nil, err
+		}
+	}
+
+	if cfg.Debug {
 		DB = DB.Debug()
 	}
 
-	if os.Getenv("DB_MIGRATION") == "true" {
-		DB.AutoMigrate(&entity.Product{})
+	return
+}
+
+func closeAll(sqlDB *sql.DB, replicas []Replica) {
+	sqlDB.Close()
+	for _, replica := range replicas {
+		replica.DB.Close()
+	}
+}
+
+func dsn(cfg config.DatabaseConfig, host string, port int) string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8&parseTime=True&loc=Local",
+		cfg.Username,
+		cfg.Password,
+		host,
+		port,
+		cfg.Name,
+	)
+}
+
+func CloseDB(db *gorm.DB, replicas []Replica) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	errs := []error{sqlDB.Close()}
+	for _, replica := range replicas {
+		errs = append(errs, replica.DB.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
+
+func CheckMigration(ctx context.Context, db *gorm.DB) error {
+	if !db.WithContext(ctx).Migrator().HasTable(&entity.Product{}) {
+		return errors.New("products table has not been migrated")
 	}
 
-	return DB
+	return nil
 }
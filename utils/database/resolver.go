@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type primaryKey struct{}
+
+// WithPrimary marks the context so that reads made with it are served by the
+// primary instead of a replica, giving read-your-writes consistency.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func UsePrimary(ctx context.Context) bool {
+	usePrimary, _ := ctx.Value(primaryKey{}).(bool)
+	return usePrimary
+}
+
+// Conn returns the session repositories should use for ctx. Inside a
+// transaction started by a TxManager the transaction is used, otherwise reads
+// go to a replica when one is configured unless the context was marked
+// WithPrimary.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+
+	conn := db.WithContext(ctx)
+	if UsePrimary(ctx) {
+		conn = conn.Clauses(dbresolver.Write)
+	}
+	return conn
+}
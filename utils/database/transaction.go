@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+func TxFromContext(ctx context.Context) (tx *gorm.DB, ok bool) {
+	tx, ok = ctx.Value(txKey{}).(*gorm.DB)
+	return
+}
+
+func InTransaction(ctx context.Context) bool {
+	_, ok := TxFromContext(ctx)
+	return ok
+}
+
+func ParseIsolation(level string) (isolation sql.IsolationLevel, err error) {
+	switch level {
+	case "", "default":
+		isolation = sql.LevelDefault
+	case "read-uncommitted":
+		isolation = sql.LevelReadUncommitted
+	case "read-committed":
+		isolation = sql.LevelReadCommitted
+	case "repeatable-read":
+		isolation = sql.LevelRepeatableRead
+	case "serializable":
+		isolation = sql.LevelSerializable
+	default:
+		err = fmt.Errorf("unknown transaction isolation level %q", level)
+	}
+	return
+}
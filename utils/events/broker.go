@@ -0,0 +1,174 @@
+package events
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	ProductCreated = "product.created"
+	ProductUpdated = "product.updated"
+	ProductDeleted = "product.deleted"
+)
+
+var (
+	// ErrSlowConsumer closes a subscription whose buffer filled up. The client
+	// is expected to reconnect and resume from the last event it received.
+	ErrSlowConsumer = errors.New("subscriber is too slow")
+	ErrClosed       = errors.New("broker is closed")
+)
+
+type Event struct {
+	ID   uint64      `json:"-"`
+	Type string      `json:"type"`
+	Key  string      `json:"key"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// Filter selects the events delivered to a subscription.
+type Filter func(Event) bool
+
+type Publisher interface {
+	Publish(eventType, key string, data interface{}) Event
+}
+
+type Subscription interface {
+	Events() <-chan Event
+	// Err reports why Events was closed, it is nil after Close.
+	Err() error
+	Close()
+}
+
+type Broker interface {
+	Publisher
+	// Subscribe registers a subscription and returns the buffered events
+	// published after lastEventID. complete is false when some of those
+	// events were already evicted from the replay buffer.
+	Subscribe(lastEventID uint64, filter Filter) (sub Subscription, replay []Event, complete bool)
+	Close()
+}
+
+type defaultBroker struct {
+	mu           sync.Mutex
+	lastID       uint64
+	replay       []Event
+	replaySize   int
+	clientBuffer int
+	subscribers  map[*subscription]struct{}
+	closed       bool
+}
+
+func NewBroker(replaySize, clientBuffer int) Broker {
+	return &defaultBroker{
+		replaySize:   replaySize,
+		clientBuffer: clientBuffer,
+		subscribers:  make(map[*subscription]struct{}),
+	}
+}
+
+func (b *defaultBroker) Publish(eventType, key string, data interface{}) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := Event{ID: b.lastID, Type: eventType, Key: key, Time: time.Now().UTC(), Data: data}
+	if b.closed {
+		return event
+	}
+
+	if b.replaySize > 0 {
+		if len(b.replay) == b.replaySize {
+			copy(b.replay, b.replay[1:])
+			b.replay = b.replay[:len(b.replay)-1]
+		}
+		b.replay = append(b.replay, event)
+	}
+
+	for sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub, ErrSlowConsumer)
+		}
+	}
+	return event
+}
+
+func (b *defaultBroker) Subscribe(lastEventID uint64, filter Filter) (Subscription, []Event, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &subscription{broker: b, filter: filter, events: make(chan Event, b.clientBuffer)}
+	if b.closed {
+		sub.err = ErrClosed
+		close(sub.events)
+		return sub, nil, false
+	}
+	b.subscribers[sub] = struct{}{}
+
+	if lastEventID == 0 {
+		return sub, nil, true
+	}
+
+	complete := lastEventID <= b.lastID
+	if len(b.replay) > 0 && b.replay[0].ID > lastEventID+1 {
+		complete = false
+	}
+
+	var replay []Event
+	for _, event := range b.replay {
+		if event.ID > lastEventID && (filter == nil || filter(event)) {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay, complete
+}
+
+func (b *defaultBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		b.remove(sub, ErrClosed)
+	}
+}
+
+func (b *defaultBroker) remove(sub *subscription, err error) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+
+	delete(b.subscribers, sub)
+	sub.err = err
+	close(sub.events)
+}
+
+type subscription struct {
+	broker *defaultBroker
+	filter Filter
+	events chan Event
+	err    error
+}
+
+func (s *subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *subscription) Err() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.err
+}
+
+func (s *subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s, nil)
+}
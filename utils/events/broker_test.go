@@ -0,0 +1,121 @@
+package events
+
+import (
+	"testing"
+)
+
+func Test_defaultBroker_Subscribe(t *testing.T) {
+	tests := []struct {
+		name         string
+		replaySize   int
+		published    int
+		lastEventID  uint64
+		filter       Filter
+		wantReplay   []uint64
+		wantComplete bool
+	}{
+		{
+			name:         "new subscriber",
+			replaySize:   10,
+			published:    3,
+			wantComplete: true,
+		},
+		{
+			name:         "resume from buffer",
+			replaySize:   10,
+			published:    5,
+			lastEventID:  3,
+			wantReplay:   []uint64{4, 5},
+			wantComplete: true,
+		},
+		{
+			name:         "resume when up to date",
+			replaySize:   10,
+			published:    5,
+			lastEventID:  5,
+			wantComplete: true,
+		},
+		{
+			name:         "events evicted from buffer",
+			replaySize:   2,
+			published:    5,
+			lastEventID:  1,
+			wantReplay:   []uint64{4, 5},
+			wantComplete: false,
+		},
+		{
+			name:         "unknown event ID after restart",
+			replaySize:   10,
+			published:    2,
+			lastEventID:  7,
+			wantComplete: false,
+		},
+		{
+			name:        "filtered replay",
+			replaySize:  10,
+			published:   5,
+			lastEventID: 1,
+			filter: func(event Event) bool {
+				return event.ID%2 == 0
+			},
+			wantReplay:   []uint64{2, 4},
+			wantComplete: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := NewBroker(tt.replaySize, 1)
+			for i := 0; i < tt.published; i++ {
+				broker.Publish(ProductCreated, "key", nil)
+			}
+
+			_, replay, complete := broker.Subscribe(tt.lastEventID, tt.filter)
+			if complete != tt.wantComplete {
+				t.Errorf("defaultBroker.Subscribe() complete = %v, want %v", complete, tt.wantComplete)
+			}
+			if len(replay) != len(tt.wantReplay) {
+				t.Fatalf("defaultBroker.Subscribe() replay = %v, want IDs %v", replay, tt.wantReplay)
+			}
+			for i, event := range replay {
+				if event.ID != tt.wantReplay[i] {
+					t.Errorf("defaultBroker.Subscribe() replay[%d] = %v, want %v", i, event.ID, tt.wantReplay[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_defaultBroker_Publish(t *testing.T) {
+	broker := NewBroker(0, 2)
+	all, _, _ := broker.Subscribe(0, nil)
+	filtered, _, _ := broker.Subscribe(0, func(event Event) bool {
+		return event.Key == "b"
+	})
+
+	broker.Publish(ProductCreated, "a", nil)
+	broker.Publish(ProductUpdated, "b", nil)
+
+	if got := (<-filtered.Events()).Key; got != "b" {
+		t.Errorf("filtered subscription received key %v, want b", got)
+	}
+
+	broker.Publish(ProductDeleted, "c", nil)
+	if _, ok := <-all.Events(); !ok {
+		t.Fatalf("slow subscription closed before its buffered events were read")
+	}
+	<-all.Events()
+	if _, ok := <-all.Events(); ok {
+		t.Errorf("slow subscription was not closed")
+	}
+	if all.Err() != ErrSlowConsumer {
+		t.Errorf("slow subscription Err() = %v, want %v", all.Err(), ErrSlowConsumer)
+	}
+
+	broker.Close()
+	if _, ok := <-filtered.Events(); ok {
+		t.Errorf("subscription was not closed with the broker")
+	}
+	if filtered.Err() != ErrClosed {
+		t.Errorf("closed subscription Err() = %v, want %v", filtered.Err(), ErrClosed)
+	}
+}
@@ -0,0 +1,203 @@
+package events
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var filterOperators = []string{"==", "!=", ">=", "<=", "~=", ">", "<"}
+
+type condition struct {
+	field    string
+	operator string
+	value    string
+}
+
+// ParseFilter compiles an expression such as
+//
+//	type == product.updated and rating >= 4 and title ~= "mie"
+//
+// into a Filter. Conditions are joined with and; type and key refer to the
+// event, any other field to the JSON name of a field of the event data. ~=
+// matches a case insensitive substring. Numbers are compared numerically.
+func ParseFilter(expr string) (Filter, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("filter is empty")
+	}
+
+	var conditions []condition
+	for i := 0; i < len(tokens); i += 4 {
+		if i+3 > len(tokens) {
+			return nil, fmt.Errorf("incomplete condition %q", strings.Join(tokens[i:], " "))
+		}
+
+		cond := condition{field: tokens[i], operator: tokens[i+1], value: unquote(tokens[i+2])}
+		if !isOperator(cond.operator) {
+			return nil, fmt.Errorf("unknown operator %q", cond.operator)
+		}
+		if isOperator(cond.field) {
+			return nil, fmt.Errorf("expected a field name before %q", cond.operator)
+		}
+		if isOperator(tokens[i+2]) {
+			return nil, fmt.Errorf("expected a value after %q", cond.operator)
+		}
+		conditions = append(conditions, cond)
+
+		if i+3 < len(tokens) && !strings.EqualFold(tokens[i+3], "and") {
+			return nil, fmt.Errorf("expected and, got %q", tokens[i+3])
+		}
+		if i+3 == len(tokens)-1 {
+			return nil, fmt.Errorf("expected a condition after and")
+		}
+	}
+
+	return func(event Event) bool {
+		for _, cond := range conditions {
+			if !cond.match(event) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func (c condition) match(event Event) bool {
+	actual, ok := fieldValue(event, c.field)
+	if !ok {
+		return false
+	}
+
+	if c.operator == "~=" {
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(c.value))
+	}
+
+	compared := strings.Compare(actual, c.value)
+	if a, err := strconv.ParseFloat(actual, 64); err == nil {
+		if b, err := strconv.ParseFloat(c.value, 64); err == nil {
+			compared = 0
+			if a < b {
+				compared = -1
+			} else if a > b {
+				compared = 1
+			}
+		}
+	}
+
+	switch c.operator {
+	case "==":
+		return compared == 0
+	case "!=":
+		return compared != 0
+	case ">":
+		return compared > 0
+	case ">=":
+		return compared >= 0
+	case "<":
+		return compared < 0
+	case "<=":
+		return compared <= 0
+	}
+	return false
+}
+
+func fieldValue(event Event, field string) (string, bool) {
+	switch field {
+	case "type":
+		return event.Type, true
+	case "key":
+		return event.Key, true
+	}
+
+	data := reflect.ValueOf(event.Data)
+	for data.Kind() == reflect.Pointer || data.Kind() == reflect.Interface {
+		if data.IsNil() {
+			return "", false
+		}
+		data = data.Elem()
+	}
+
+	var value reflect.Value
+	switch data.Kind() {
+	case reflect.Map:
+		if data.Type().Key().Kind() != reflect.String {
+			return "", false
+		}
+		value = data.MapIndex(reflect.ValueOf(field))
+	case reflect.Struct:
+		for i := 0; i < data.NumField(); i++ {
+			name, _, _ := strings.Cut(data.Type().Field(i).Tag.Get("json"), ",")
+			if name == field {
+				value = data.Field(i)
+				break
+			}
+		}
+	}
+	if !value.IsValid() || !value.CanInterface() {
+		return "", false
+	}
+	return fmt.Sprint(value.Interface()), true
+}
+
+func tokenize(expr string) (tokens []string, err error) {
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end + 1
+		case strings.ContainsRune("=!<>~", r):
+			end := i + 1
+			if end < len(runes) && runes[end] == '=' {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("=!<>~\"", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+	return
+}
+
+func unquote(token string) string {
+	if !strings.HasPrefix(token, `"`) {
+		return token
+	}
+	if value, err := strconv.Unquote(token); err == nil {
+		return value
+	}
+	return strings.Trim(token, `"`)
+}
+
+func isOperator(token string) bool {
+	for _, operator := range filterOperators {
+		if token == operator {
+			return true
+		}
+	}
+	return false
+}
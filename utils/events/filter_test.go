@@ -0,0 +1,57 @@
+package events
+
+import (
+	"testing"
+)
+
+type filterProduct struct {
+	ID     string  `json:"id"`
+	Title  string  `json:"title"`
+	Rating float64 `json:"rating"`
+}
+
+func TestParseFilter(t *testing.T) {
+	updated := Event{Type: ProductUpdated, Key: "p1", Data: filterProduct{ID: "p1", Title: "Mie Goreng", Rating: 4.5}}
+	deleted := Event{Type: ProductDeleted, Key: "p2"}
+
+	tests := []struct {
+		name    string
+		expr    string
+		event   Event
+		want    bool
+		wantErr bool
+	}{
+		{name: "type matches", expr: "type == product.updated", event: updated, want: true},
+		{name: "type differs", expr: "type != product.updated", event: updated, want: false},
+		{name: "key matches", expr: `key == "p2"`, event: deleted, want: true},
+		{name: "numeric comparison", expr: "rating >= 4", event: updated, want: true},
+		{name: "numeric is not lexical", expr: "rating > 10", event: updated, want: false},
+		{name: "contains ignores case", expr: `title ~= "mie"`, event: updated, want: true},
+		{name: "quoted value with spaces", expr: `title == "Mie Goreng"`, event: updated, want: true},
+		{name: "all conditions must match", expr: "type == product.updated AND rating < 4", event: updated, want: false},
+		{name: "no spaces around operator", expr: "rating<=4.5 and id==p1", event: updated, want: true},
+		{name: "data field of event without data", expr: "rating >= 0", event: deleted, want: false},
+		{name: "unknown field", expr: "price > 1", event: updated, want: false},
+		{name: "empty", expr: " ", wantErr: true},
+		{name: "unknown operator", expr: "rating => 4", wantErr: true},
+		{name: "missing value", expr: "rating >=", wantErr: true},
+		{name: "trailing and", expr: "rating >= 4 and", wantErr: true},
+		{name: "or is not supported", expr: "rating >= 4 or rating < 1", wantErr: true},
+		{name: "unterminated string", expr: `title == "mie`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseFilter(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := filter(tt.event); got != tt.want {
+				t.Errorf("filter(%v) = %v, want %v", tt.event.Type, got, tt.want)
+			}
+		})
+	}
+}
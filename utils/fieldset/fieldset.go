@@ -0,0 +1,221 @@
+package fieldset
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/fadilahonespot/library/errors"
+	"github.com/labstack/echo/v4"
+)
+
+// Fieldset is the selection of a response: the fields asked for with
+// ?fields= and the optional sections asked for with ?expand=. The zero
+// Fieldset selects every field and expands nothing.
+type Fieldset struct {
+	Fields []string
+	Expand []string
+}
+
+// Parse reads ?fields= and ?expand= of c. Fields must be JSON fields of
+// resource and sections one of sections, others are refused with 400.
+func Parse(c echo.Context, resource interface{}, sections ...string) (f Fieldset, err error) {
+	known := make(map[string]bool)
+	for _, name := range jsonNames(reflect.TypeOf(resource)) {
+		known[name] = true
+	}
+	expandable := make(map[string]bool)
+	for _, section := range sections {
+		delete(known, section)
+		expandable[section] = true
+	}
+
+	var unknown []string
+	f.Fields, unknown = split(c.QueryParam("fields"), known)
+	if len(unknown) > 0 {
+		return f, errors.SetError(http.StatusBadRequest, "Unknown fields: "+strings.Join(unknown, ", "))
+	}
+	f.Expand, unknown = split(c.QueryParam("expand"), expandable)
+	if len(unknown) > 0 {
+		return f, errors.SetError(http.StatusBadRequest, "Unknown sections to expand: "+strings.Join(unknown, ", "))
+	}
+	return
+}
+
+// Has reports whether field is selected or is an expanded section.
+func (f Fieldset) Has(field string) bool {
+	return len(f.Fields) == 0 || contains(f.Fields, field) || contains(f.Expand, field)
+}
+
+// Expands reports whether section is expanded.
+func (f Fieldset) Expands(section string) bool {
+	return contains(f.Expand, section)
+}
+
+// Columns returns the columns of the selected fields and expanded sections,
+// nil when every field is selected.
+func (f Fieldset) Columns(columns map[string][]string) (selected []string) {
+	if len(f.Fields) == 0 {
+		return nil
+	}
+
+	for _, name := range append(append([]string{}, f.Fields...), f.Expand...) {
+		for _, column := range columns[name] {
+			if !contains(selected, column) {
+				selected = append(selected, column)
+			}
+		}
+	}
+	return
+}
+
+// Apply returns the JSON form of v, an object or a list of objects, with only
+// the selected fields, in the order of v.
+func (f Fieldset) Apply(v interface{}) (interface{}, error) {
+	if len(f.Fields) == 0 {
+		return v, nil
+	}
+	return each(v, f.pick)
+}
+
+// ApplyTo is Apply for the objects in the key member of v, an object or a
+// list of objects, such as the product of batch results.
+func (f Fieldset) ApplyTo(v interface{}, key string) (interface{}, error) {
+	if len(f.Fields) == 0 {
+		return v, nil
+	}
+	return each(v, func(body []byte) (json.RawMessage, error) {
+		return members(body, func(name string, value json.RawMessage) (json.RawMessage, error) {
+			if name != key || value[0] != '{' {
+				return value, nil
+			}
+			return f.pick(value)
+		})
+	})
+}
+
+func (f Fieldset) pick(body []byte) (json.RawMessage, error) {
+	return members(body, func(name string, value json.RawMessage) (json.RawMessage, error) {
+		if !f.Has(name) {
+			return nil, nil
+		}
+		return value, nil
+	})
+}
+
+// each calls apply with the JSON form of v when it is an object, or of each
+// of its items when it is a list.
+func each(v interface{}, apply func(body []byte) (json.RawMessage, error)) (interface{}, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	switch body[0] {
+	case '{':
+		return apply(body)
+	case '[':
+		var items []json.RawMessage
+		err = json.Unmarshal(body, &items)
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			items[i], err = apply(items[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return v, nil
+}
+
+// members rewrites the members of the JSON object body in order, leaving out
+// those rewrite returns nil for.
+func members(body []byte, rewrite func(name string, value json.RawMessage) (json.RawMessage, error)) (json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	_, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		err = decoder.Decode(&value)
+		if err != nil {
+			return nil, err
+		}
+
+		key, _ := token.(string)
+		value, err = rewrite(key, value)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func split(list string, known map[string]bool) (names, unknown []string) {
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || contains(names, name) {
+			continue
+		}
+		if !known[name] {
+			unknown = append(unknown, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	return
+}
+
+func jsonNames(t reflect.Type) (names []string) {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
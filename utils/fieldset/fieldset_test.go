@@ -0,0 +1,157 @@
+package fieldset
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/fadilahonespot/library/errors"
+	"github.com/labstack/echo/v4"
+)
+
+type audit struct {
+	CreatedAt string `json:"createdAt"`
+}
+
+type product struct {
+	ID     string  `json:"id"`
+	Title  string  `json:"title"`
+	Rating float64 `json:"rating"`
+	Audit  *audit  `json:"audit,omitempty"`
+	secret string
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		want     Fieldset
+		wantCode int
+	}{
+		{name: "every field", query: ""},
+		{name: "empty fields", query: "?fields=&expand="},
+		{name: "fields and sections", query: "?fields=title,%20id,title&expand=audit", want: Fieldset{Fields: []string{"title", "id"}, Expand: []string{"audit"}}},
+		{name: "unknown fields", query: "?fields=id,price,stock", wantCode: http.StatusBadRequest},
+		{name: "section as a field", query: "?fields=audit", wantCode: http.StatusBadRequest},
+		{name: "unexported field", query: "?fields=secret", wantCode: http.StatusBadRequest},
+		{name: "unknown section", query: "?expand=reviews", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/products"+tt.query, nil)
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			got, err := Parse(c, []product{}, "audit")
+			if tt.wantCode != 0 {
+				appErr, ok := err.(*errors.ApplicationError)
+				if !ok || appErr.ErrorCode != tt.wantCode {
+					t.Fatalf("Parse() error = %v, want code %d", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldset_Apply(t *testing.T) {
+	item := product{ID: "22c8e385", Title: "Mie indomi Rasa ayam Soto", Rating: 8.1, Audit: &audit{CreatedAt: "2026-10-01"}}
+
+	tests := []struct {
+		name     string
+		fieldset Fieldset
+		value    interface{}
+		want     string
+	}{
+		{
+			name:  "every field",
+			value: item,
+			want:  `{"id":"22c8e385","title":"Mie indomi Rasa ayam Soto","rating":8.1,"audit":{"createdAt":"2026-10-01"}}`,
+		},
+		{
+			name:     "object in the order of its fields",
+			fieldset: Fieldset{Fields: []string{"rating", "id"}},
+			value:    item,
+			want:     `{"id":"22c8e385","rating":8.1}`,
+		},
+		{
+			name:     "list with an expanded section",
+			fieldset: Fieldset{Fields: []string{"title"}, Expand: []string{"audit"}},
+			value:    []product{item, {Title: "Mie Sedap"}},
+			want:     `[{"title":"Mie indomi Rasa ayam Soto","audit":{"createdAt":"2026-10-01"}},{"title":"Mie Sedap"}]`,
+		},
+		{
+			name:     "empty list",
+			fieldset: Fieldset{Fields: []string{"title"}},
+			value:    []product(nil),
+			want:     `null`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fieldset.Apply(tt.value)
+			if err != nil {
+				t.Fatalf("Fieldset.Apply() error = %v", err)
+			}
+			body, _ := json.Marshal(got)
+			if string(body) != tt.want {
+				t.Errorf("Fieldset.Apply() = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldset_ApplyTo(t *testing.T) {
+	type result struct {
+		ID      string   `json:"id"`
+		Found   bool     `json:"found"`
+		Product *product `json:"product"`
+	}
+	results := []result{
+		{ID: "22c8e385", Found: true, Product: &product{ID: "22c8e385", Title: "Mie indomi Rasa ayam Soto", Rating: 8.1}},
+		{ID: "a1b91cb9"},
+	}
+
+	got, err := Fieldset{Fields: []string{"title"}}.ApplyTo(results, "product")
+	if err != nil {
+		t.Fatalf("Fieldset.ApplyTo() error = %v", err)
+	}
+	body, _ := json.Marshal(got)
+	want := `[{"id":"22c8e385","found":true,"product":{"title":"Mie indomi Rasa ayam Soto"}},{"id":"a1b91cb9","found":false,"product":null}]`
+	if string(body) != want {
+		t.Errorf("Fieldset.ApplyTo() = %s, want %s", body, want)
+	}
+}
+
+func TestFieldset_Columns(t *testing.T) {
+	columns := map[string][]string{
+		"id":        {"id"},
+		"title":     {"title"},
+		"createdAt": {"created_at"},
+		"audit":     {"created_at", "updated_at"},
+	}
+
+	tests := []struct {
+		name     string
+		fieldset Fieldset
+		want     []string
+	}{
+		{name: "every column", fieldset: Fieldset{Expand: []string{"audit"}}},
+		{name: "selected fields", fieldset: Fieldset{Fields: []string{"title", "id"}}, want: []string{"title", "id"}},
+		{name: "expanded sections", fieldset: Fieldset{Fields: []string{"createdAt"}, Expand: []string{"audit"}}, want: []string{"created_at", "updated_at"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fieldset.Columns(columns); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fieldset.Columns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	defaultCheckTimeout = 2 * time.Second
+)
+
+var ErrShuttingDown = errors.New("service is shutting down")
+
+type CheckerFunc func(ctx context.Context) error
+
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+func (r Report) IsUp() bool {
+	return r.Status == StatusUp
+}
+
+type Registry interface {
+	Register(name string, checker CheckerFunc)
+	Shutdown()
+	Liveness(ctx context.Context) Report
+	Readiness(ctx context.Context) Report
+}
+
+type defaultRegistry struct {
+	mu           sync.RWMutex
+	checkers     map[string]CheckerFunc
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewRegistry(timeout time.Duration) Registry {
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+
+	return &defaultRegistry{
+		checkers: make(map[string]CheckerFunc),
+		timeout:  timeout,
+	}
+}
+
+func (r *defaultRegistry) Register(name string, checker CheckerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers[name] = checker
+}
+
+func (r *defaultRegistry) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+func (r *defaultRegistry) Liveness(ctx context.Context) Report {
+	return Report{Status: StatusUp}
+}
+
+func (r *defaultRegistry) Readiness(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := make(map[string]CheckerFunc, len(r.checkers))
+	for name, checker := range r.checkers {
+		checkers[name] = checker
+	}
+	r.mu.RUnlock()
+
+	report := Report{
+		Status: StatusUp,
+		Checks: make(map[string]CheckResult, len(checkers)+1),
+	}
+
+	if r.shuttingDown.Load() {
+		report.Status = StatusDown
+		report.Checks["lifecycle"] = CheckResult{Status: StatusDown, Error: ErrShuttingDown.Error()}
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for name, checker := range checkers {
+		wg.Add(1)
+		go func(name string, checker CheckerFunc) {
+			defer wg.Done()
+			result := r.run(ctx, checker)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(name, checker)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (r *defaultRegistry) run(ctx context.Context, checker CheckerFunc) (result CheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker(ctx)
+	result.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	result.Status = StatusUp
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return
+}
@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_defaultRegistry_Readiness(t *testing.T) {
+	tests := []struct {
+		name         string
+		checkers     map[string]CheckerFunc
+		shuttingDown bool
+		wantStatus   string
+		wantDown     []string
+	}{
+		{
+			name:       "no checkers registered",
+			wantStatus: StatusUp,
+		},
+		{
+			name: "all checkers up",
+			checkers: map[string]CheckerFunc{
+				"database": func(ctx context.Context) error { return nil },
+			},
+			wantStatus: StatusUp,
+		},
+		{
+			name: "one checker down",
+			checkers: map[string]CheckerFunc{
+				"database":  func(ctx context.Context) error { return nil },
+				"migration": func(ctx context.Context) error { return errors.New("not migrated") },
+			},
+			wantStatus: StatusDown,
+			wantDown:   []string{"migration"},
+		},
+		{
+			name: "checker exceeds timeout",
+			checkers: map[string]CheckerFunc{
+				"database": func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			},
+			wantStatus: StatusDown,
+			wantDown:   []string{"database"},
+		},
+		{
+			name: "shutting down",
+			checkers: map[string]CheckerFunc{
+				"database": func(ctx context.Context) error { return nil },
+			},
+			shuttingDown: true,
+			wantStatus:   StatusDown,
+			wantDown:     []string{"lifecycle"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(50 * time.Millisecond)
+			for name, checker := range tt.checkers {
+				registry.Register(name, checker)
+			}
+			if tt.shuttingDown {
+				registry.Shutdown()
+			}
+
+			report := registry.Readiness(context.TODO())
+			if report.Status != tt.wantStatus {
+				t.Errorf("defaultRegistry.Readiness() status = %v, want %v", report.Status, tt.wantStatus)
+			}
+
+			for _, name := range tt.wantDown {
+				if report.Checks[name].Status != StatusDown {
+					t.Errorf("defaultRegistry.Readiness() check %v = %v, want %v", name, report.Checks[name].Status, StatusDown)
+				}
+			}
+		})
+	}
+}
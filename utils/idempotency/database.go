@@ -0,0 +1,113 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+// databaseStore shares the keys between instances through the
+// idempotency_keys table. Keys are hashed so they fit the key_hash column.
+type databaseStore struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewDatabaseStore(db *gorm.DB) Store {
+	return &databaseStore{
+		db:  db,
+		now: time.Now,
+	}
+}
+
+func (s *databaseStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (existing Record, reserved bool, err error) {
+	now := s.now()
+	s.sweep(ctx, now)
+
+	// Reads go to the primary, a replica may not have seen the reservation yet.
+	db := s.db.WithContext(ctx).Clauses(dbresolver.Write)
+	row := entity.IdempotencyKey{
+		KeyHash:     hashKey(key),
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(ttl),
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+	if result.Error != nil {
+		return Record{}, false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return Record{}, true, nil
+	}
+
+	// The key is known, take it over only once it has expired.
+	result = db.Model(&entity.IdempotencyKey{}).
+		Where("key_hash = ? AND expires_at <= ?", row.KeyHash, now).
+		Select("fingerprint", "completed", "status_code", "header", "body", "expires_at").
+		Updates(&row)
+	if result.Error != nil {
+		return Record{}, false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return Record{}, true, nil
+	}
+
+	var current entity.IdempotencyKey
+	err = db.Where("key_hash = ?", row.KeyHash).Take(&current).Error
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	return Record{
+		Fingerprint: current.Fingerprint,
+		Completed:   current.Completed,
+		StatusCode:  current.StatusCode,
+		Header:      current.Header,
+		Body:        current.Body,
+		ExpiresAt:   current.ExpiresAt,
+	}, false, nil
+}
+
+func (s *databaseStore) Complete(ctx context.Context, key string, record Record) (err error) {
+	return s.db.WithContext(ctx).Model(&entity.IdempotencyKey{}).
+		Where("key_hash = ?", hashKey(key)).
+		Select("completed", "status_code", "header", "body").
+		Updates(&entity.IdempotencyKey{
+			Completed:  true,
+			StatusCode: record.StatusCode,
+			Header:     record.Header,
+			Body:       record.Body,
+		}).Error
+}
+
+func (s *databaseStore) Release(ctx context.Context, key string) (err error) {
+	return s.db.WithContext(ctx).Where("key_hash = ?", hashKey(key)).Delete(&entity.IdempotencyKey{}).Error
+}
+
+// sweep deletes expired keys at most once per sweepInterval on each instance.
+func (s *databaseStore) sweep(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < sweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&entity.IdempotencyKey{})
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,107 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"gorm.io/gorm"
+)
+
+const sweepInterval = time.Minute
+
+type Record struct {
+	Fingerprint string
+	Completed   bool
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+type Store interface {
+	// Reserve atomically records key as in flight. When the key is already
+	// known the existing record is returned and reserved is false.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (existing Record, reserved bool, err error)
+	Complete(ctx context.Context, key string, record Record) (err error)
+	Release(ctx context.Context, key string) (err error)
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	records   map[string]Record
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{
+		records: make(map[string]Record),
+		now:     time.Now,
+	}
+}
+
+func (s *memoryStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (existing Record, reserved bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if record, ok := s.records[key]; ok && now.Before(record.ExpiresAt) {
+		return record, false, nil
+	}
+
+	s.records[key] = Record{
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(ttl),
+	}
+	return Record{}, true, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, key string, record Record) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.records[key]
+	if !ok {
+		return
+	}
+
+	record.Fingerprint = existing.Fingerprint
+	record.ExpiresAt = existing.ExpiresAt
+	record.Completed = true
+	s.records[key] = record
+	return
+}
+
+func (s *memoryStore) Release(ctx context.Context, key string) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return
+}
+
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+
+	s.lastSweep = now
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// NewStore returns the store selected by cfg.Store.
+func NewStore(cfg config.IdempotencyConfig, db *gorm.DB) Store {
+	if cfg.Store == "database" {
+		return NewDatabaseStore(db)
+	}
+	return NewMemoryStore()
+}
@@ -0,0 +1,18 @@
+package identity
+
+import "context"
+
+type contextKey struct{}
+
+// WithIdentity records the authenticated caller, such as the identity of a
+// client certificate.
+func WithIdentity(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+// FromContext returns the authenticated caller, or an empty string for an
+// anonymous one.
+func FromContext(ctx context.Context) string {
+	name, _ := ctx.Value(contextKey{}).(string)
+	return name
+}
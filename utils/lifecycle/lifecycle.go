@@ -0,0 +1,133 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/utils/logger"
+)
+
+const defaultShutdownTimeout = 15 * time.Second
+
+// Hook is a subsystem managed by the lifecycle. OnStart must not block, long
+// running work should be started in a goroutine and report failures with
+// Lifecycle.Fail. Hooks are stopped in the reverse order they were appended.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+type Lifecycle interface {
+	Append(hook Hook)
+	Fail(err error)
+	Run() error
+}
+
+type defaultLifecycle struct {
+	mu              sync.Mutex
+	hooks           []Hook
+	errCh           chan error
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+	log             logger.Logger
+}
+
+func NewLifecycle(shutdownTimeout time.Duration, log logger.Logger) Lifecycle {
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+
+	return &defaultLifecycle{
+		errCh:           make(chan error, 1),
+		shutdownTimeout: shutdownTimeout,
+		signals:         []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+		log:             log,
+	}
+}
+
+func (l *defaultLifecycle) Append(hook Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, hook)
+}
+
+func (l *defaultLifecycle) Fail(err error) {
+	if err == nil {
+		return
+	}
+
+	select {
+	case l.errCh <- err:
+	default:
+	}
+}
+
+func (l *defaultLifecycle) Run() (err error) {
+	ctx, stop := signal.NotifyContext(context.Background(), l.signals...)
+	defer stop()
+
+	return l.run(ctx)
+}
+
+func (l *defaultLifecycle) run(ctx context.Context) (err error) {
+	l.mu.Lock()
+	hooks := make([]Hook, len(l.hooks))
+	copy(hooks, l.hooks)
+	l.mu.Unlock()
+
+	started, err := l.start(ctx, hooks)
+	if err == nil {
+		select {
+		case <-ctx.Done():
+			l.log.Info(context.Background(), "Shutdown signal received")
+		case err = <-l.errCh:
+			l.log.Error(context.Background(), "subsystem failed", err.Error())
+		}
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), l.shutdownTimeout)
+	defer cancel()
+
+	stopErr := l.stop(stopCtx, started)
+	return errors.Join(err, stopErr)
+}
+
+func (l *defaultLifecycle) start(ctx context.Context, hooks []Hook) (started []Hook, err error) {
+	for _, hook := range hooks {
+		if hook.OnStart != nil {
+			l.log.Info(ctx, "Starting "+hook.Name)
+			err = hook.OnStart(ctx)
+			if err != nil {
+				l.log.Error(ctx, "failed to start "+hook.Name, err.Error())
+				err = fmt.Errorf("start %s: %w", hook.Name, err)
+				return
+			}
+		}
+		started = append(started, hook)
+	}
+	return
+}
+
+func (l *defaultLifecycle) stop(ctx context.Context, started []Hook) (err error) {
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		hook := started[i]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		l.log.Info(ctx, "Stopping "+hook.Name)
+		if stopErr := hook.OnStop(ctx); stopErr != nil {
+			l.log.Error(ctx, "failed to stop "+hook.Name, stopErr.Error())
+			errs = append(errs, fmt.Errorf("stop %s: %w", hook.Name, stopErr))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,88 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/fadilahonespot/simple-api/utils/logger"
+)
+
+func Test_defaultLifecycle_run(t *testing.T) {
+	logger.NewLogger(config.LoggerConfig{})
+
+	tests := []struct {
+		name      string
+		startErr  error
+		failErr   error
+		stopErr   error
+		wantOrder []string
+		wantErr   bool
+	}{
+		{
+			name:      "stop hooks in reverse order on signal",
+			wantOrder: []string{"start:database", "start:worker", "start:http", "stop:http", "stop:worker", "stop:database"},
+			wantErr:   false,
+		},
+		{
+			name:      "subsystem failure triggers shutdown",
+			failErr:   errors.New("listen failed"),
+			wantOrder: []string{"start:database", "start:worker", "start:http", "stop:http", "stop:worker", "stop:database"},
+			wantErr:   true,
+		},
+		{
+			name:      "start failure stops only started hooks",
+			startErr:  errors.New("worker failed"),
+			wantOrder: []string{"start:database", "start:worker", "stop:database"},
+			wantErr:   true,
+		},
+		{
+			name:      "stop failure still stops remaining hooks",
+			stopErr:   errors.New("drain timeout"),
+			wantOrder: []string{"start:database", "start:worker", "start:http", "stop:http", "stop:worker", "stop:database"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var order []string
+			newHook := func(name string, startErr, stopErr error) Hook {
+				return Hook{
+					Name: name,
+					OnStart: func(ctx context.Context) error {
+						order = append(order, "start:"+name)
+						return startErr
+					},
+					OnStop: func(ctx context.Context) error {
+						order = append(order, "stop:"+name)
+						return stopErr
+					},
+				}
+			}
+
+			app := NewLifecycle(time.Second, logger.New(logger.NewRecorder(), nil)).(*defaultLifecycle)
+			app.Append(newHook("database", nil, nil))
+			app.Append(newHook("worker", tt.startErr, nil))
+			app.Append(newHook("http", nil, tt.stopErr))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.failErr != nil {
+				app.Fail(tt.failErr)
+			} else {
+				cancel()
+			}
+			defer cancel()
+
+			if err := app.run(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("defaultLifecycle.run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("defaultLifecycle.run() order = %v, want %v", order, tt.wantOrder)
+			}
+		})
+	}
+}
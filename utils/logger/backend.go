@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fadilahonespot/library/logres"
+	"github.com/fadilahonespot/simple-api/config"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	BackendLogres = "logres"
+	BackendSlog   = "slog"
+)
+
+// Backend writes entries that already passed the level and sampling checks.
+type Backend interface {
+	Log(ctx context.Context, name string, level Level, title string, message []interface{})
+	TDR(ctx context.Context, request []byte, response []byte)
+}
+
+func newBackend(cfg config.LoggerConfig) Backend {
+	if cfg.Backend == BackendSlog {
+		if !cfg.LogsWrite {
+			return NewSlogBackend(os.Stdout, os.Stdout)
+		}
+
+		os.MkdirAll(cfg.FolderPath, os.ModePerm)
+		return NewSlogBackend(rotatingFile(cfg.FolderPath, "sys.log"), rotatingFile(cfg.FolderPath, "tdr.log"))
+	}
+
+	return NewLogresBackend(logres.SetLogger(logres.LogresConfig{
+		MaxSize:    1,
+		MaxBackups: 5,
+		MaxAge:     7,
+		Compress:   true,
+		LocalTime:  true,
+		FolderPath: cfg.FolderPath,
+		LogsWrite:  cfg.LogsWrite,
+	}))
+}
+
+func rotatingFile(folder, name string) io.Writer {
+	return &lumberjack.Logger{
+		Filename:   filepath.Join(folder, name),
+		MaxSize:    1,
+		MaxBackups: 5,
+		MaxAge:     7,
+		Compress:   true,
+		LocalTime:  true,
+	}
+}
+
+type logresBackend struct {
+	log logres.Logres
+}
+
+// NewLogresBackend only has INFO and ERROR records, debug and warn entries
+// are written as INFO with the level in front of the title.
+func NewLogresBackend(log logres.Logres) Backend {
+	return &logresBackend{log: log}
+}
+
+func (b *logresBackend) Log(ctx context.Context, name string, level Level, title string, message []interface{}) {
+	message = withTrace(ctx, message)
+	switch {
+	case level >= LevelError:
+		b.log.Error(ctx, title, message...)
+	case level == LevelInfo:
+		b.log.Info(ctx, title, message...)
+	default:
+		b.log.Info(ctx, fmt.Sprintf("[%s] %s", level, title), message...)
+	}
+}
+
+func (b *logresBackend) TDR(ctx context.Context, request []byte, response []byte) {
+	b.log.TDR(ctx, request, response)
+}
+
+type slogBackend struct {
+	syslog *slog.Logger
+	tdrlog *slog.Logger
+}
+
+// NewSlogBackend writes JSON records with the same SYS and TDR fields as
+// logres, plus the logger name and the level.
+func NewSlogBackend(sys, tdr io.Writer) Backend {
+	options := &slog.HandlerOptions{Level: LevelDebug}
+	return &slogBackend{
+		syslog: slog.New(slog.NewJSONHandler(sys, options)),
+		tdrlog: slog.New(slog.NewJSONHandler(tdr, options)),
+	}
+}
+
+func (b *slogBackend) Log(ctx context.Context, name string, level Level, title string, message []interface{}) {
+	attrs := make([]slog.Attr, 0, len(message)+4)
+	if name != "" {
+		attrs = append(attrs, slog.String("logger", name))
+	}
+	attrs = append(attrs, slog.Any("SYS", logres.GetCtxLogger(ctx)))
+	for i, item := range message {
+		attrs = append(attrs, slog.Any(fmt.Sprintf("message_%d", i), item))
+	}
+
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if spanCtx.IsValid() {
+		attrs = append(attrs, slog.String("trace_id", spanCtx.TraceID().String()), slog.String("span_id", spanCtx.SpanID().String()))
+	}
+	b.syslog.LogAttrs(ctx, level, title, attrs...)
+}
+
+func (b *slogBackend) TDR(ctx context.Context, request []byte, response []byte) {
+	contextLog := logres.GetCtxLogger(ctx)
+	tdr := logres.LogTdrModel{
+		RequestId: contextLog.ThreadID,
+		Path:      contextLog.ReqURI,
+		Method:    contextLog.ReqMethod,
+		Port:      contextLog.ServicePort,
+		RespTime:  time.Since(logres.GetRequestTimeFromContext(ctx)).Milliseconds(),
+		Error:     logres.GetErrorMessageFromContext(ctx),
+	}
+	json.Unmarshal(request, &tdr.Request)
+	json.Unmarshal(response, &tdr.Response)
+
+	if header, ok := contextLog.Header.(http.Header); ok {
+		headers := make(map[string]string, len(header))
+		for key := range header {
+			headers[key] = header.Get(key)
+		}
+		tdr.Header = headers
+	}
+
+	if resp, ok := tdr.Response.(map[string]interface{}); ok && resp["code"] != nil {
+		tdr.ResponseCode = fmt.Sprint(resp["code"])
+	}
+
+	b.tdrlog.LogAttrs(ctx, LevelInfo, "TDR", slog.Any("TDR", tdr))
+}
+
+func withTrace(ctx context.Context, message []interface{}) []interface{} {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return message
+	}
+
+	return append(message, map[string]string{
+		"trace_id": spanCtx.TraceID().String(),
+		"span_id":  spanCtx.SpanID().String(),
+	})
+}
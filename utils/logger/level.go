@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+type Level = slog.Level
+
+const (
+	LevelDebug = slog.LevelDebug
+	LevelInfo  = slog.LevelInfo
+	LevelWarn  = slog.LevelWarn
+	LevelError = slog.LevelError
+)
+
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", name)
+}
+
+// LevelsReport is the JSON form of Levels.
+type LevelsReport struct {
+	Default  string            `json:"default"`
+	Packages map[string]string `json:"packages"`
+}
+
+// LevelChange sets the level of Package, or the default level when Package
+// is empty. An empty Level resets Package to the default level.
+type LevelChange struct {
+	Package string `json:"package,omitempty"`
+	Level   string `json:"level,omitempty"`
+}
+
+// Levels holds the minimum level of every named logger, adjustable at runtime.
+type Levels struct {
+	mu       sync.RWMutex
+	fallback Level
+	packages map[string]Level
+}
+
+func NewLevels(fallback Level) *Levels {
+	return &Levels{fallback: fallback, packages: make(map[string]Level)}
+}
+
+func (l *Levels) Enabled(name string, level Level) bool {
+	return level >= l.Level(name)
+}
+
+func (l *Levels) Level(name string) Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if level, ok := l.packages[name]; ok {
+		return level
+	}
+	return l.fallback
+}
+
+func (l *Levels) Set(name string, level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if name == "" {
+		l.fallback = level
+		return
+	}
+	l.packages[name] = level
+}
+
+func (l *Levels) Reset(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.packages, name)
+}
+
+func (l *Levels) Apply(change LevelChange) error {
+	if change.Level == "" {
+		if change.Package == "" {
+			return fmt.Errorf("the default level cannot be reset")
+		}
+		l.Reset(change.Package)
+		return nil
+	}
+
+	level, err := ParseLevel(change.Level)
+	if err != nil {
+		return err
+	}
+	l.Set(change.Package, level)
+	return nil
+}
+
+// replace swaps every level at once, used when the configuration is reloaded.
+func (l *Levels) replace(fallback Level, packages map[string]Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fallback = fallback
+	l.packages = packages
+}
+
+func (l *Levels) Report() LevelsReport {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	report := LevelsReport{Default: l.fallback.String(), Packages: make(map[string]string, len(l.packages))}
+	for name, level := range l.packages {
+		report.Packages[name] = level.String()
+	}
+	return report
+}
+
+// parsePackageLevels parses entries such as usecase=debug.
+func parsePackageLevels(entries []string) (map[string]Level, error) {
+	levels := make(map[string]Level, len(entries))
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("log level %q is not in the form package=level", entry)
+		}
+
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		levels[strings.TrimSpace(name)] = level
+	}
+	return levels, nil
+}
@@ -3,38 +3,151 @@ package logger
 import (
 	"context"
 	"os"
+	"sync"
 
-	"github.com/fadilahonespot/library/logres"
+	"github.com/fadilahonespot/simple-api/config"
 )
 
-var logresLog logres.Logres
+type Logger interface {
+	Debug(ctx context.Context, title string, message ...interface{})
+	Info(ctx context.Context, title string, message ...interface{})
+	Warn(ctx context.Context, title string, message ...interface{})
+	Error(ctx context.Context, title string, message ...interface{})
+	TDR(ctx context.Context, request []byte, response []byte)
+	Enabled(level Level) bool
+	// Named returns a logger whose level is set under name.
+	Named(name string) Logger
+	// Sampled returns a logger for hot paths that drops repeated entries below
+	// the error level, see LoggerConfig.Sampling.
+	Sampled() Logger
+}
 
-func NewLogger() {
-	loggerWritter := false
-	if os.Getenv("LOGGER_LOGS_WRITE") == "true" {
-		loggerWritter = true
-	}
-	config := logres.LogresConfig{
-		MaxSize:    1,
-		MaxBackups: 5,
-		MaxAge:     7,
-		Compress:   true,
-		LocalTime:  true,
-		FolderPath: os.Getenv("LOGGER_FOLDER_PATH"),
-		LogsWrite:  loggerWritter,
+type state struct {
+	mu      sync.RWMutex
+	backend Backend
+	levels  *Levels
+	sampler *sampler
+}
+
+// global is configured in place by NewLogger, so loggers taken from Named
+// before that keep working.
+var global = &state{
+	backend: NewSlogBackend(os.Stdout, os.Stdout),
+	levels:  NewLevels(LevelInfo),
+}
+
+// NewLogger configures the package logger, cfg must be validated.
+func NewLogger(cfg config.LoggerConfig) {
+	fallback, _ := ParseLevel(cfg.Level)
+	packages, _ := parsePackageLevels(cfg.Levels)
+	global.levels.replace(fallback, packages)
+
+	backend := newBackend(cfg)
+
+	global.mu.Lock()
+	defer global.mu.Unlock()
+
+	global.backend = backend
+	global.sampler = newSampler(cfg.Sampling.First, cfg.Sampling.Thereafter, cfg.Sampling.Tick)
+}
+
+// New returns a logger on its own backend, mostly for tests. Every level is
+// enabled when levels is nil.
+func New(backend Backend, levels *Levels) Logger {
+	if levels == nil {
+		levels = NewLevels(LevelDebug)
 	}
-	
-	logresLog = logres.SetLogger(config)
+	return &defaultLogger{state: &state{backend: backend, levels: levels}}
+}
+
+func Named(name string) Logger {
+	return &defaultLogger{state: global, name: name}
+}
+
+func Default() Logger {
+	return std
+}
+
+// GetLevels returns the levels of the package logger.
+func GetLevels() *Levels {
+	return global.levels
+}
+
+var std = Named("")
+
+func Debug(ctx context.Context, title string, message ...interface{}) {
+	std.Debug(ctx, title, message...)
 }
 
 func Info(ctx context.Context, title string, message ...interface{}) {
-	logresLog.Info(ctx, title, message...)
+	std.Info(ctx, title, message...)
+}
+
+func Warn(ctx context.Context, title string, message ...interface{}) {
+	std.Warn(ctx, title, message...)
 }
 
 func Error(ctx context.Context, title string, message ...interface{}) {
-	logresLog.Error(ctx, title, message)
+	std.Error(ctx, title, message...)
 }
 
 func TDR(ctx context.Context, request []byte, response []byte) {
-	logresLog.TDR(ctx, request, response)
+	std.TDR(ctx, request, response)
+}
+
+type defaultLogger struct {
+	state   *state
+	name    string
+	sampled bool
+}
+
+func (l *defaultLogger) Debug(ctx context.Context, title string, message ...interface{}) {
+	l.log(ctx, LevelDebug, title, message)
+}
+
+func (l *defaultLogger) Info(ctx context.Context, title string, message ...interface{}) {
+	l.log(ctx, LevelInfo, title, message)
+}
+
+func (l *defaultLogger) Warn(ctx context.Context, title string, message ...interface{}) {
+	l.log(ctx, LevelWarn, title, message)
+}
+
+func (l *defaultLogger) Error(ctx context.Context, title string, message ...interface{}) {
+	l.log(ctx, LevelError, title, message)
+}
+
+func (l *defaultLogger) TDR(ctx context.Context, request []byte, response []byte) {
+	l.state.mu.RLock()
+	backend := l.state.backend
+	l.state.mu.RUnlock()
+
+	backend.TDR(ctx, request, response)
+}
+
+func (l *defaultLogger) Enabled(level Level) bool {
+	return l.state.levels.Enabled(l.name, level)
+}
+
+func (l *defaultLogger) Named(name string) Logger {
+	return &defaultLogger{state: l.state, name: name, sampled: l.sampled}
+}
+
+func (l *defaultLogger) Sampled() Logger {
+	return &defaultLogger{state: l.state, name: l.name, sampled: true}
+}
+
+func (l *defaultLogger) log(ctx context.Context, level Level, title string, message []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	l.state.mu.RLock()
+	backend, sampler := l.state.backend, l.state.sampler
+	l.state.mu.RUnlock()
+
+	if l.sampled && level < LevelWarn && !sampler.allow(level, title) {
+		return
+	}
+	backend.Log(ctx, l.name, level, title, message)
 }
@@ -0,0 +1,209 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/library/logres"
+)
+
+func Test_defaultLogger_log(t *testing.T) {
+	tests := []struct {
+		name      string
+		fallback  Level
+		packages  map[string]Level
+		logger    string
+		log       func(log Logger)
+		wantTitle []string
+	}{
+		{
+			name:     "drop entries below the default level",
+			fallback: LevelInfo,
+			logger:   "usecase",
+			log: func(log Logger) {
+				log.Debug(context.Background(), "debug")
+				log.Info(context.Background(), "info")
+				log.Warn(context.Background(), "warn")
+				log.Error(context.Background(), "error")
+			},
+			wantTitle: []string{"info", "warn", "error"},
+		},
+		{
+			name:     "package level overrides the default level",
+			fallback: LevelInfo,
+			packages: map[string]Level{"usecase": LevelError},
+			logger:   "usecase",
+			log: func(log Logger) {
+				log.Info(context.Background(), "info")
+				log.Error(context.Background(), "error")
+				log.Named("handler").Info(context.Background(), "handler info")
+			},
+			wantTitle: []string{"error", "handler info"},
+		},
+		{
+			name:     "sampled logger keeps warnings and errors",
+			fallback: LevelDebug,
+			logger:   "http",
+			log: func(log Logger) {
+				log = log.Sampled()
+				for i := 0; i < 3; i++ {
+					log.Info(context.Background(), "info")
+					log.Warn(context.Background(), "warn")
+				}
+			},
+			wantTitle: []string{"info", "warn", "warn", "warn"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := NewLevels(tt.fallback)
+			for name, level := range tt.packages {
+				levels.Set(name, level)
+			}
+			recorder := NewRecorder()
+			log := &defaultLogger{state: &state{backend: recorder, levels: levels, sampler: newSampler(1, 0, time.Minute)}, name: tt.logger}
+
+			tt.log(log)
+
+			var titles []string
+			for _, entry := range recorder.Entries() {
+				titles = append(titles, entry.Title)
+			}
+			if len(titles) != len(tt.wantTitle) {
+				t.Fatalf("logged %v, want %v", titles, tt.wantTitle)
+			}
+			for i := range titles {
+				if titles[i] != tt.wantTitle[i] {
+					t.Errorf("logged %v, want %v", titles, tt.wantTitle)
+				}
+			}
+		})
+	}
+}
+
+func Test_sampler_allow(t *testing.T) {
+	tests := []struct {
+		name       string
+		first      int
+		thereafter int
+		calls      int
+		tickAfter  int
+		want       int
+	}{
+		{name: "first entries only", first: 2, thereafter: 0, calls: 10, want: 2},
+		{name: "every thereafter-th entry", first: 2, thereafter: 3, calls: 11, want: 5},
+		{name: "new window after tick", first: 2, thereafter: 0, calls: 6, tickAfter: 3, want: 4},
+		{name: "disabled", first: 0, thereafter: 0, calls: 5, want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			s := newSampler(tt.first, tt.thereafter, time.Second)
+			s.now = func() time.Time { return now }
+
+			var allowed int
+			for i := 0; i < tt.calls; i++ {
+				if tt.tickAfter > 0 && i == tt.tickAfter {
+					now = now.Add(time.Second)
+				}
+				if s.allow(LevelInfo, "title") {
+					allowed++
+				}
+			}
+			if allowed != tt.want {
+				t.Errorf("sampler.allow() allowed %d of %d, want %d", allowed, tt.calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevels_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []LevelChange
+		want    LevelsReport
+		wantErr bool
+	}{
+		{
+			name:    "set the default level",
+			changes: []LevelChange{{Level: "WARN"}},
+			want:    LevelsReport{Default: "WARN", Packages: map[string]string{}},
+		},
+		{
+			name:    "set and reset a package level",
+			changes: []LevelChange{{Package: "usecase", Level: "debug"}, {Package: "rpc", Level: "error"}, {Package: "rpc"}},
+			want:    LevelsReport{Default: "INFO", Packages: map[string]string{"usecase": "DEBUG"}},
+		},
+		{
+			name:    "unknown level",
+			changes: []LevelChange{{Package: "usecase", Level: "trace"}},
+			wantErr: true,
+		},
+		{
+			name:    "reset the default level",
+			changes: []LevelChange{{}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := NewLevels(LevelInfo)
+			var err error
+			for _, change := range tt.changes {
+				err = levels.Apply(change)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Levels.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got := levels.Report()
+			if got.Default != tt.want.Default || len(got.Packages) != len(tt.want.Packages) {
+				t.Fatalf("Levels.Report() = %+v, want %+v", got, tt.want)
+			}
+			for name, level := range tt.want.Packages {
+				if got.Packages[name] != level {
+					t.Errorf("Levels.Report() = %+v, want %+v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func Test_slogBackend_Log(t *testing.T) {
+	var sys, tdr bytes.Buffer
+	backend := NewSlogBackend(&sys, &tdr)
+	ctx := logres.SetCtxLogger(context.Background(), logres.Context{ThreadID: "request-1", ReqMethod: "GET", ReqURI: "/products"})
+
+	backend.Log(ctx, "usecase", LevelWarn, "product is already exist", []interface{}{"title", 1})
+	backend.TDR(ctx, []byte(`{"title":"a"}`), []byte(`{"code":"400"}`))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(sys.Bytes(), &entry); err != nil {
+		t.Fatalf("sys log %q is not JSON: %v", sys.String(), err)
+	}
+	want := map[string]interface{}{"level": "WARN", "msg": "product is already exist", "logger": "usecase", "message_0": "title", "message_1": float64(1)}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("sys log %s = %v, want %v", key, entry[key], value)
+		}
+	}
+	if sysCtx, _ := entry["SYS"].(map[string]interface{}); sysCtx["app_thread_id"] != "request-1" {
+		t.Errorf("sys log SYS = %v, want thread ID request-1", entry["SYS"])
+	}
+
+	var record struct {
+		TDR logres.LogTdrModel `json:"TDR"`
+	}
+	if err := json.Unmarshal(tdr.Bytes(), &record); err != nil {
+		t.Fatalf("tdr log %q is not JSON: %v", tdr.String(), err)
+	}
+	if record.TDR.RequestId != "request-1" || record.TDR.Path != "/products" || record.TDR.ResponseCode != "400" {
+		t.Errorf("tdr log = %+v, want request-1 on /products with code 400", record.TDR)
+	}
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+type Entry struct {
+	Name    string
+	Level   Level
+	Title   string
+	Message []interface{}
+}
+
+type TDREntry struct {
+	Request  []byte
+	Response []byte
+}
+
+// Recorder is a Backend that keeps entries in memory so unit tests can assert
+// on them, use it with New(logger.NewRecorder(), nil).
+type Recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+	tdrs    []TDREntry
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Log(ctx context.Context, name string, level Level, title string, message []interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, Entry{Name: name, Level: level, Title: title, Message: message})
+}
+
+func (r *Recorder) TDR(ctx context.Context, request []byte, response []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tdrs = append(r.tdrs, TDREntry{Request: request, Response: response})
+}
+
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Entry(nil), r.entries...)
+}
+
+func (r *Recorder) TDRs() []TDREntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]TDREntry(nil), r.tdrs...)
+}
+
+func (r *Recorder) Has(level Level, title string) bool {
+	for _, entry := range r.Entries() {
+		if entry.Level == level && entry.Title == title {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+	r.tdrs = nil
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// maxSamplerKeys bounds the windows kept for titles built from request data.
+const maxSamplerKeys = 1000
+
+type samplerKey struct {
+	level Level
+	title string
+}
+
+type samplerWindow struct {
+	start time.Time
+	count int
+}
+
+// sampler lets the first entries of every level and title through in each
+// tick, then only every thereafter-th one.
+type sampler struct {
+	first      int
+	thereafter int
+	tick       time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	windows map[samplerKey]*samplerWindow
+}
+
+func newSampler(first, thereafter int, tick time.Duration) *sampler {
+	return &sampler{
+		first:      first,
+		thereafter: thereafter,
+		tick:       tick,
+		now:        time.Now,
+		windows:    make(map[samplerKey]*samplerWindow),
+	}
+}
+
+func (s *sampler) allow(level Level, title string) bool {
+	if s == nil || s.first <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	key := samplerKey{level: level, title: title}
+	window, ok := s.windows[key]
+	if !ok || now.Sub(window.start) >= s.tick {
+		if len(s.windows) > maxSamplerKeys {
+			s.windows = make(map[samplerKey]*samplerWindow)
+		}
+		window = &samplerWindow{start: now}
+		s.windows[key] = window
+	}
+
+	window.count++
+	if window.count <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (window.count-s.first)%s.thereafter == 0
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const gormStartKey = "metrics:start"
+
+func RegisterGormCallbacks(db *gorm.DB) (err error) {
+	callback := db.Callback()
+	err = errors.Join(
+		callback.Create().Before("gorm:create").Register("metrics:before_create", gormBefore),
+		callback.Create().After("gorm:create").Register("metrics:after_create", gormAfter("create")),
+		callback.Query().Before("gorm:query").Register("metrics:before_query", gormBefore),
+		callback.Query().After("gorm:query").Register("metrics:after_query", gormAfter("query")),
+		callback.Update().Before("gorm:update").Register("metrics:before_update", gormBefore),
+		callback.Update().After("gorm:update").Register("metrics:after_update", gormAfter("update")),
+		callback.Delete().Before("gorm:delete").Register("metrics:before_delete", gormBefore),
+		callback.Delete().After("gorm:delete").Register("metrics:after_delete", gormAfter("delete")),
+		callback.Row().Before("gorm:row").Register("metrics:before_row", gormBefore),
+		callback.Row().After("gorm:row").Register("metrics:after_row", gormAfter("row")),
+		callback.Raw().Before("gorm:raw").Register("metrics:before_raw", gormBefore),
+		callback.Raw().After("gorm:raw").Register("metrics:after_raw", gormAfter("raw")),
+	)
+	return
+}
+
+func gormBefore(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+func gormAfter(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		DBQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			DBQueryErrorsTotal.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
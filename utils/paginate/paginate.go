@@ -7,16 +7,22 @@ import (
 )
 
 type Pagination struct {
-	Page   int
-	Limit  int
+	Page  int
+	Limit int
+	// Offset, when set, is the number of items skipped instead of the pages
+	// before Page.
+	Offset int
 	Title  string
 	Rating float64
 }
 
 func Paginate(page, length int) func(db *gorm.DB) *gorm.DB {
-	return func(db *gorm.DB) *gorm.DB {
+	return PaginateOffset((page-1)*length, length)
+}
 
-		offset := (page - 1) * length
+// PaginateOffset skips offset items and reads at most length items.
+func PaginateOffset(offset, length int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
 		return db.Offset(offset).Limit(length)
 	}
 }
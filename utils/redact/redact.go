@@ -0,0 +1,276 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fadilahonespot/simple-api/config"
+)
+
+const Mask = "******"
+
+// Patterns are the built-in value patterns selectable by name.
+var Patterns = map[string]*regexp.Regexp{
+	"email":  regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	"card":   regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+	"bearer": regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+	"jwt":    regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+}
+
+type Redactor interface {
+	// Header returns a copy of header with denied headers masked.
+	Header(header http.Header) http.Header
+	// URL returns u with masked field query parameters.
+	URL(u *url.URL) string
+	// Body returns body as a JSON document for logger.TDR: JSON with masked
+	// fields, other text as a string, binary content as a short description.
+	Body(contentType string, body []byte) []byte
+	String(value string) string
+}
+
+type field []string
+
+type defaultRedactor struct {
+	headers     map[string]bool
+	fields      []field
+	patterns    []*regexp.Regexp
+	maxBodySize int
+}
+
+// NewRedactor expects cfg to be validated, custom patterns that do not
+// compile panic.
+func NewRedactor(cfg config.RedactionConfig) Redactor {
+	r := &defaultRedactor{headers: make(map[string]bool), maxBodySize: cfg.MaxBodySize}
+	for _, header := range cfg.Headers {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, path := range cfg.Fields {
+		r.fields = append(r.fields, strings.Split(strings.ToLower(path), "."))
+	}
+	for _, name := range cfg.Patterns {
+		if pattern, ok := Patterns[name]; ok {
+			r.patterns = append(r.patterns, pattern)
+		}
+	}
+	for _, expr := range cfg.CustomPatterns {
+		r.patterns = append(r.patterns, regexp.MustCompile(expr))
+	}
+	return r
+}
+
+func (r *defaultRedactor) Header(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for key, values := range header {
+		masked := make([]string, len(values))
+		for i, value := range values {
+			if r.headers[http.CanonicalHeaderKey(key)] {
+				masked[i] = Mask
+			} else {
+				masked[i] = r.String(value)
+			}
+		}
+		redacted[key] = masked
+	}
+	return redacted
+}
+
+func (r *defaultRedactor) URL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+
+	redacted := *u
+	redacted.RawQuery = r.values(u.Query()).Encode()
+	return redacted.String()
+}
+
+func (r *defaultRedactor) Body(contentType string, body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case isJSON(mediaType) || (mediaType == "" && json.Valid(body)):
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if decoder.Decode(&value) == nil {
+			redacted, err := marshal(r.value(value, nil))
+			if err == nil {
+				return r.truncate(redacted, false)
+			}
+		}
+		return r.truncate([]byte(r.String(string(body))), true)
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			return r.truncate([]byte(r.values(values).Encode()), true)
+		}
+		return r.truncate([]byte(r.String(string(body))), true)
+	case isText(mediaType):
+		return r.truncate([]byte(r.String(string(body))), true)
+	}
+
+	return quote(fmt.Sprintf("[%s body of %d bytes omitted]", mediaType, len(body)))
+}
+
+func (r *defaultRedactor) String(value string) string {
+	for _, pattern := range r.patterns {
+		value = pattern.ReplaceAllStringFunc(value, func(match string) string {
+			if pattern == Patterns["card"] && !luhn(match) {
+				return match
+			}
+			return Mask
+		})
+	}
+	return value
+}
+
+func (r *defaultRedactor) value(value interface{}, path []string) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			child := append(path[:len(path):len(path)], strings.ToLower(key))
+			if r.isField(child) && item != nil {
+				value[key] = Mask
+				continue
+			}
+			value[key] = r.value(item, child)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = r.value(item, append(path[:len(path):len(path)], strconv.Itoa(i)))
+		}
+	case string:
+		return r.String(value)
+	}
+	return value
+}
+
+func (r *defaultRedactor) values(values url.Values) url.Values {
+	redacted := make(url.Values, len(values))
+	for key, items := range values {
+		masked := make([]string, len(items))
+		for i, item := range items {
+			if r.isField([]string{strings.ToLower(key)}) {
+				masked[i] = Mask
+			} else {
+				masked[i] = r.String(item)
+			}
+		}
+		redacted[key] = masked
+	}
+	return redacted
+}
+
+// isField reports whether path matches a configured field. A field of a
+// single name matches that key at any depth, * matches any key or index.
+func (r *defaultRedactor) isField(path []string) bool {
+	for _, f := range r.fields {
+		if len(f) == 1 {
+			if f[0] == path[len(path)-1] {
+				return true
+			}
+			continue
+		}
+
+		if len(f) != len(path) {
+			continue
+		}
+		matched := true
+		for i := range f {
+			if f[i] != "*" && f[i] != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate cuts body to the configured size. A cut JSON document is no longer
+// valid, so it is then logged as a string like any other text.
+func (r *defaultRedactor) truncate(body []byte, text bool) []byte {
+	if len(body) <= r.maxBodySize {
+		if text {
+			return quote(string(body))
+		}
+		return body
+	}
+
+	cut := body[:r.maxBodySize]
+	for i := len(cut) - 1; i >= 0 && i >= len(cut)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(cut[i]) {
+			if !utf8.FullRune(cut[i:]) {
+				cut = cut[:i]
+			}
+			break
+		}
+	}
+	return quote(fmt.Sprintf("%s...[truncated %d bytes]", cut, len(body)-len(cut)))
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func isText(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "", "application/xml", "application/javascript", "application/graphql":
+		return true
+	}
+	return false
+}
+
+func quote(value string) []byte {
+	quoted, _ := marshal(value)
+	return quoted
+}
+
+// marshal encodes value without escaping HTML characters, which would only
+// make the logs harder to read.
+func marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(value)
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), err
+}
+
+// luhn tells card numbers from other long digit runs such as timestamps.
+func luhn(number string) bool {
+	var sum int
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		if number[i] < '0' || number[i] > '9' {
+			continue
+		}
+
+		digit := int(number[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,149 @@
+package redact
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/fadilahonespot/simple-api/config"
+)
+
+func newTestRedactor(maxBodySize int) Redactor {
+	return NewRedactor(config.RedactionConfig{
+		Headers:        []string{"Authorization", "cookie"},
+		Fields:         []string{"password", "card.number", "items.*.secret"},
+		Patterns:       []string{"email", "card", "bearer", "jwt"},
+		CustomPatterns: []string{`sk_live_[0-9a-z]+`},
+		MaxBodySize:    maxBodySize,
+	})
+}
+
+func Test_defaultRedactor_Body(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxBodySize int
+		want        string
+	}{
+		{
+			name:        "empty",
+			contentType: "application/json",
+			want:        "",
+		},
+		{
+			name:        "field at any depth",
+			contentType: "application/json; charset=UTF-8",
+			body:        `{"user":{"Password":"hunter2","name":"budi"}}`,
+			want:        `{"user":{"Password":"******","name":"budi"}}`,
+		},
+		{
+			name:        "dotted path from the root",
+			contentType: "application/json",
+			body:        `{"card":{"number":"x"},"other":{"number":"y"}}`,
+			want:        `{"card":{"number":"******"},"other":{"number":"y"}}`,
+		},
+		{
+			name:        "wildcard path",
+			contentType: "application/json",
+			body:        `{"items":[{"secret":"a","id":1},{"secret":null}]}`,
+			want:        `{"items":[{"id":1,"secret":"******"},{"secret":null}]}`,
+		},
+		{
+			name:        "patterns in values",
+			contentType: "application/json",
+			body:        `{"note":"mail budi@example.com, card 4111 1111 1111 1111, order 1234567890123","key":"sk_live_abc123"}`,
+			want:        `{"key":"******","note":"mail ******, card ******, order 1234567890123"}`,
+		},
+		{
+			name:        "numbers keep their precision",
+			contentType: "application/json",
+			body:        `{"rating":4.50,"id":12345678901234567890}`,
+			want:        `{"id":12345678901234567890,"rating":4.50}`,
+		},
+		{
+			name:        "invalid json is logged as a string",
+			contentType: "application/json",
+			body:        `{"password":`,
+			want:        `"{\"password\":"`,
+		},
+		{
+			name:        "form fields",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "password=hunter2&title=mie",
+			want:        `"password=%2A%2A%2A%2A%2A%2A&title=mie"`,
+		},
+		{
+			name:        "plain text",
+			contentType: "text/plain",
+			body:        "Authorization: Bearer abc.def",
+			want:        `"Authorization: ******"`,
+		},
+		{
+			name:        "binary content",
+			contentType: "image/png",
+			body:        "\x89PNG\r\n",
+			want:        `"[image/png body of 6 bytes omitted]"`,
+		},
+		{
+			name:        "truncated json",
+			contentType: "application/json",
+			body:        `{"title":"mie goreng"}`,
+			maxBodySize: 10,
+			want:        `"{\"title\":\"...[truncated 12 bytes]"`,
+		},
+		{
+			name:        "truncation keeps runes whole",
+			contentType: "text/plain",
+			body:        "añb",
+			maxBodySize: 2,
+			want:        `"a...[truncated 3 bytes]"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxBodySize := tt.maxBodySize
+			if maxBodySize == 0 {
+				maxBodySize = 4096
+			}
+
+			got := string(newTestRedactor(maxBodySize).Body(tt.contentType, []byte(tt.body)))
+			if got != tt.want {
+				t.Errorf("defaultRedactor.Body() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_defaultRedactor_Header(t *testing.T) {
+	header := http.Header{
+		"Authorization": {"Bearer abc"},
+		"Cookie":        {"a=1", "b=2"},
+		"X-Forwarded":   {"for budi@example.com"},
+		"Accept":        {"application/json"},
+	}
+
+	got := newTestRedactor(4096).Header(header)
+	if got.Get("Authorization") != Mask || strings.Join(got.Values("Cookie"), ",") != Mask+","+Mask {
+		t.Errorf("Header() = %v, want Authorization and Cookie masked", got)
+	}
+	if got.Get("X-Forwarded") != "for "+Mask || got.Get("Accept") != "application/json" {
+		t.Errorf("Header() = %v, want other values redacted by pattern only", got)
+	}
+	if header.Get("Authorization") != "Bearer abc" {
+		t.Errorf("Header() modified its argument")
+	}
+}
+
+func Test_defaultRedactor_URL(t *testing.T) {
+	u, _ := url.Parse("/products?password=x&title=mie")
+	if got, want := newTestRedactor(4096).URL(u), "/products?password=%2A%2A%2A%2A%2A%2A&title=mie"; got != want {
+		t.Errorf("URL() = %s, want %s", got, want)
+	}
+
+	u, _ = url.Parse("/products/1")
+	if got := newTestRedactor(4096).URL(u); got != "/products/1" {
+		t.Errorf("URL() = %s, want /products/1", got)
+	}
+}
@@ -0,0 +1,46 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const maxLength = 128
+
+// Resolve returns the request ID of a call: the first valid value of the
+// InboundHeaders of cfg read through header when inbound IDs are trusted,
+// otherwise the trace ID of ctx or a new UUID.
+func Resolve(ctx context.Context, cfg config.RequestIDConfig, header func(name string) string) string {
+	if cfg.TrustInbound {
+		for _, name := range cfg.InboundHeaders {
+			if id := header(name); IsValid(id) {
+				return id
+			}
+		}
+	}
+
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if spanCtx.HasTraceID() {
+		return spanCtx.TraceID().String()
+	}
+
+	return uuid.New().String()
+}
+
+// IsValid reports whether id is safe to log and echo back: at most 128
+// printable ASCII characters without spaces.
+func IsValid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
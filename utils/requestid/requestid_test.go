@@ -0,0 +1,66 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fadilahonespot/simple-api/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestResolve(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	traced := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	trusted := config.RequestIDConfig{InboundHeaders: []string{"X-Request-ID", "X-Correlation-ID"}, TrustInbound: true}
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		cfg          config.RequestIDConfig
+		headers      map[string]string
+		want         string
+		wantGenerate bool
+	}{
+		{name: "first inbound header", ctx: traced, cfg: trusted, headers: map[string]string{"X-Request-ID": "req-123", "X-Correlation-ID": "corr-456"}, want: "req-123"},
+		{name: "next inbound header", ctx: traced, cfg: trusted, headers: map[string]string{"X-Correlation-ID": "corr-456"}, want: "corr-456"},
+		{name: "invalid inbound id", ctx: traced, cfg: trusted, headers: map[string]string{"X-Request-ID": "bad id"}, want: traceID.String()},
+		{name: "untrusted inbound id", ctx: traced, cfg: config.RequestIDConfig{InboundHeaders: []string{"X-Request-ID"}}, headers: map[string]string{"X-Request-ID": "req-123"}, want: traceID.String()},
+		{name: "generated id", ctx: context.Background(), cfg: trusted, wantGenerate: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Resolve(tt.ctx, tt.cfg, func(name string) string {
+				return tt.headers[name]
+			})
+			if tt.wantGenerate {
+				if len(got) != 36 {
+					t.Errorf("Resolve() = %v, want a new UUID", got)
+				}
+			} else if got != tt.want {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: "req-123", want: true},
+		{id: strings.Repeat("x", 128), want: true},
+		{id: ""},
+		{id: strings.Repeat("x", 129)},
+		{id: "bad id"},
+		{id: "bad\nid"},
+		{id: "bädid"},
+	}
+	for _, tt := range tests {
+		if got := IsValid(tt.id); got != tt.want {
+			t.Errorf("IsValid(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+func RegisterGormCallbacks(db *gorm.DB) (err error) {
+	callback := db.Callback()
+	err = errors.Join(
+		callback.Create().Before("gorm:create").Register("tracing:before_create", gormBefore("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", gormAfter),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", gormBefore("query")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", gormAfter),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", gormBefore("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", gormAfter),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", gormBefore("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", gormAfter),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", gormBefore("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", gormAfter),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", gormBefore("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", gormAfter),
+	)
+	return
+}
+
+func gormBefore(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement == nil || db.Statement.Context == nil {
+			return
+		}
+
+		_, span := Tracer().Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemMySQL,
+				semconv.DBOperation(operation),
+			),
+		)
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func gormAfter(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBSQLTable(db.Statement.Table),
+		semconv.DBStatement(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}